@@ -0,0 +1,173 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// AXNode is a node of the accessibility tree.
+type AXNode struct {
+	Role string
+	Name string
+	// States are the states and properties of the node, e.g. "focused", "checked" or "disabled".
+	States   map[string]string
+	Children []*AXNode
+}
+
+// AccessibilitySnapshot returns the accessibility tree of the current page, without the ignored nodes.
+func (c *Puppet) AccessibilitySnapshot() (root *AXNode, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		nodes, err := accessibility.GetFullAXTree().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		root = axTree(nodes)
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// axTree builds the tree of the nodes, the children of ignored nodes are lifted to their parents.
+func axTree(nodes []*accessibility.Node) *AXNode {
+	if len(nodes) == 0 {
+		return nil
+	}
+	byID := make(map[accessibility.NodeID]*accessibility.Node, len(nodes))
+	for _, n := range nodes {
+		byID[n.NodeID] = n
+	}
+	var build func(n *accessibility.Node) []*AXNode
+	build = func(n *accessibility.Node) []*AXNode {
+		var children []*AXNode
+		for _, id := range n.ChildIds {
+			if child, ok := byID[id]; ok {
+				children = append(children, build(child)...)
+			}
+		}
+		if n.Ignored {
+			return children
+		}
+		node := &AXNode{
+			Role:     axString(n.Role),
+			Name:     axString(n.Name),
+			States:   map[string]string{},
+			Children: children,
+		}
+		for _, p := range n.Properties {
+			node.States[p.Name.String()] = axString(p.Value)
+		}
+		return []*AXNode{node}
+	}
+	roots := build(nodes[0])
+	if len(roots) == 1 {
+		return roots[0]
+	}
+	return &AXNode{Role: "RootWebArea", Children: roots}
+}
+
+func axString(v *accessibility.Value) string {
+	if v == nil || len(v.Value) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(v.Value, &s) == nil {
+		return s
+	}
+	return string(v.Value)
+}
+
+// A11yIssue is an accessibility issue found by AuditAccessibility.
+type A11yIssue struct {
+	// Rule is the rule violated, "image-alt", "label" or "color-contrast".
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Node is the start of the outer HTML of the node.
+	Node string `json:"node"`
+}
+
+// AuditAccessibility checks the current page for images without alternative text,
+// form fields without labels, and text with a contrast ratio below the WCAG AA level.
+func (c *Puppet) AuditAccessibility() (issues []A11yIssue, err error) {
+	return issues, c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	var issues = [];
+	function snippet(el) {
+		return el.outerHTML.slice(0, 120);
+	}
+	function visible(el) {
+		var style = window.getComputedStyle(el);
+		var rect = el.getBoundingClientRect();
+		return style.visibility !== "hidden" && style.display !== "none" && rect.width > 0 && rect.height > 0;
+	}
+	document.querySelectorAll("img").forEach(function (el) {
+		if (!el.hasAttribute("alt") && el.getAttribute("role") !== "presentation" && !el.getAttribute("aria-label") && !el.getAttribute("aria-labelledby")) {
+			issues.push({ rule: "image-alt", message: "image has no alternative text", node: snippet(el) });
+		}
+	});
+	document.querySelectorAll("input, select, textarea").forEach(function (el) {
+		var type = (el.getAttribute("type") || "").toLowerCase();
+		if (type === "hidden" || type === "submit" || type === "button" || type === "reset" || type === "image" || !visible(el)) {
+			return;
+		}
+		if (el.getAttribute("aria-label") || el.getAttribute("aria-labelledby") || el.getAttribute("title") || (el.labels && el.labels.length)) {
+			return;
+		}
+		issues.push({ rule: "label", message: "form field has no label", node: snippet(el) });
+	});
+	function parse(color) {
+		var m = color.match(/rgba?\(([\d.]+),\s*([\d.]+),\s*([\d.]+)(?:,\s*([\d.]+))?\)/);
+		return m ? [+m[1], +m[2], +m[3], m[4] === undefined ? 1 : +m[4]] : null;
+	}
+	function luminance(c) {
+		var v = c.slice(0, 3).map(function (x) {
+			x /= 255;
+			return x <= 0.03928 ? x / 12.92 : Math.pow((x + 0.055) / 1.055, 2.4);
+		});
+		return 0.2126 * v[0] + 0.7152 * v[1] + 0.0722 * v[2];
+	}
+	function background(el) {
+		for (; el && el.nodeType === 1; el = el.parentElement) {
+			var style = window.getComputedStyle(el);
+			if (style.backgroundImage !== "none") {
+				return null;
+			}
+			var bg = parse(style.backgroundColor);
+			if (bg && bg[3] === 1) {
+				return bg;
+			}
+		}
+		return [255, 255, 255, 1];
+	}
+	var walker = document.createTreeWalker(document.body || document.documentElement, NodeFilter.SHOW_TEXT);
+	var seen = new Set();
+	for (var text = walker.nextNode(); text; text = walker.nextNode()) {
+		var el = text.parentElement;
+		if (!el || seen.has(el) || !text.textContent.trim() || !visible(el)) {
+			continue;
+		}
+		seen.add(el);
+		var style = window.getComputedStyle(el);
+		var fg = parse(style.color);
+		var bg = background(el);
+		if (!fg || !bg) {
+			continue;
+		}
+		var l1 = luminance(fg), l2 = luminance(bg);
+		var ratio = (Math.max(l1, l2) + 0.05) / (Math.min(l1, l2) + 0.05);
+		var size = parseFloat(style.fontSize);
+		var large = size >= 24 || (size >= 18.66 && parseInt(style.fontWeight, 10) >= 700);
+		if (ratio < (large ? 3 : 4.5)) {
+			issues.push({ rule: "color-contrast", message: "contrast ratio " + ratio.toFixed(2) + " is below " + (large ? 3 : 4.5), node: snippet(el) });
+		}
+	}
+	return issues;
+})()`, &issues))
+}
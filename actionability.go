@@ -0,0 +1,142 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// Actionability configures the checks waited for before interacting with a node.
+type Actionability struct {
+	// Timeout is the maximum time to wait for the node to become actionable, 30 seconds if zero.
+	Timeout time.Duration
+	// StableWindow is the time the bounding box of the node has to stay unchanged, zero skips the check.
+	StableWindow time.Duration
+}
+
+// SetActionability makes Click, DoubleClick, ClickCount, SendKeys and SetValue wait until the node is actionable:
+// visible, enabled, and for clicks stable, not obscured by another node and receiving pointer events.
+// A nil a disables the checks.
+func (c *Puppet) SetActionability(a *Actionability) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if a == nil {
+		c.actionability = nil
+		return
+	}
+	v := *a
+	c.actionability = &v
+}
+
+const checkActionable = `function (pointer) {
+	if (!this.isConnected) {
+		return "detached";
+	}
+	var style = window.getComputedStyle(this);
+	var rect = this.getBoundingClientRect();
+	if (style.visibility !== "visible" || style.display === "none" || rect.width === 0 || rect.height === 0) {
+		return "not visible";
+	}
+	if (this.disabled || this.getAttribute("aria-disabled") === "true") {
+		return "disabled";
+	}
+	if (!pointer) {
+		if (this.readOnly) {
+			return "read-only";
+		}
+		return "";
+	}
+	if (style.pointerEvents === "none") {
+		return "does not receive pointer events";
+	}
+	var x = rect.left + rect.width / 2, y = rect.top + rect.height / 2;
+	if (x < 0 || y < 0 || x >= window.innerWidth || y >= window.innerHeight) {
+		this.scrollIntoView({ block: "center", inline: "center" });
+		rect = this.getBoundingClientRect();
+		x = rect.left + rect.width / 2;
+		y = rect.top + rect.height / 2;
+	}
+	var hit = document.elementFromPoint(x, y);
+	if (hit !== this && !this.contains(hit)) {
+		return "obscured by " + (hit ? hit.outerHTML.slice(0, 100) : "nothing");
+	}
+	return "";
+}`
+
+// defaultActionTimeout is the time waited for a node to become actionable if the Actionability has no timeout.
+const defaultActionTimeout = 30 * time.Second
+
+// beforeAction returns the actions to run before interacting with the first node matching the selector,
+// pointer reports whether the interaction is done with the pointer.
+func (c *Puppet) beforeAction(sel string, pointer bool) chromedp.Tasks {
+	c.mu.Lock()
+	a := c.actionability
+	window := c.clickStability
+	healing := c.healing
+	c.mu.Unlock()
+
+	tasks := chromedp.Tasks{}
+	if a == nil {
+		if pointer && window > 0 {
+			tasks = append(tasks, waitStable(sel, window))
+		}
+		return tasks
+	}
+	if a.StableWindow > window {
+		window = a.StableWindow
+	}
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = defaultActionTimeout
+	}
+	return append(tasks, chromedp.ActionFunc(func(parent context.Context, h cdp.Executor) error {
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		defer cancel()
+		var reason string
+		for {
+			// the reason is updated as each check passes
+			reason = "not found"
+			var err error
+			if pointer {
+				err = chromedp.WaitReady(sel).
+					Do(ctx, h)
+				if err == nil && window > 0 {
+					reason = "not stable"
+					err = waitStable(sel, window).
+						Do(ctx, h)
+				}
+				if err == nil {
+					err = callOn(sel, checkActionable, &reason, pointer).
+						Do(ctx, h)
+				}
+			} else {
+				// typing may target nodes inside iframes
+				err = inFrames(sel, callOn(sel, checkActionable, &reason, pointer), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+					return callOnNode(ctx, h, id, checkActionable, &reason, pointer)
+				}).Do(ctx, h)
+			}
+			if err == nil && reason == "" {
+				if healing {
+					return c.rememberNode(sel).
+						Do(parent, h)
+				}
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				if reason == "not found" {
+					err = fmt.Errorf("node %q not actionable after %v: %w", sel, timeout, ErrNodeNotFound)
+					if healing {
+						return c.withSuggestions(parent, h, sel, err)
+					}
+					return err
+				}
+				return fmt.Errorf("node %q not actionable after %v: %s: %w", sel, timeout, reason, ErrTimeout)
+			case <-time.After(time.Second / 20):
+			}
+		}
+	}))
+}
@@ -0,0 +1,181 @@
+package puppet
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AgentAction is an action decided by an agent, e.g. an LLM choosing the next step from the transcript.
+type AgentAction struct {
+	// Type is the type of the action, "navigate", "click", "type", "set_value", "submit", "back", "forward",
+	// "reload", "scroll_into_view" or "wait_visible".
+	Type     string `json:"type"`
+	Selector string `json:"selector,omitempty"`
+	Text     string `json:"text,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Step is a step of a Transcript, the action executed and the state of the page after it.
+type Step struct {
+	Index  int         `json:"index"`
+	Time   time.Time   `json:"time"`
+	Action AgentAction `json:"action"`
+	Error  string      `json:"error,omitempty"`
+	URL    string      `json:"url"`
+	Title  string      `json:"title"`
+	// View is the simplified view of the page after the action, the selectors of its elements
+	// can be used by the next action.
+	View string `json:"view"`
+	// Screenshot is the PNG screenshot of the page after the action, if enabled.
+	Screenshot []byte `json:"screenshot,omitempty"`
+}
+
+// Transcript is the numbered steps of an agent session.
+type Transcript struct {
+	mu    sync.Mutex
+	steps []Step
+}
+
+// Steps returns the steps recorded.
+func (t *Transcript) Steps() []Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Step(nil), t.steps...)
+}
+
+// MarshalJSON encodes the steps as a JSON array, the screenshots base64 encoded.
+func (t *Transcript) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.Steps())
+}
+
+// Text returns the transcript as text for a prompt, without the screenshots.
+func (t *Transcript) Text() string {
+	var sb strings.Builder
+	for _, s := range t.Steps() {
+		data, _ := json.Marshal(s.Action)
+		fmt.Fprintf(&sb, "## Step %d: %s\n", s.Index, data)
+		if s.Error != "" {
+			fmt.Fprintf(&sb, "Error: %s\n", s.Error)
+		}
+		fmt.Fprintf(&sb, "URL: %s\nTitle: %s\n\n%s\n\n", s.URL, s.Title, s.View)
+	}
+	return sb.String()
+}
+
+func (t *Transcript) add(s Step) Step {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s.Index = len(t.steps) + 1
+	t.steps = append(t.steps, s)
+	return s
+}
+
+// ActionExecutor executes the actions decided by an agent and records them into a transcript.
+type ActionExecutor interface {
+	// Execute executes the action, and returns the step recorded with the state of the page after it.
+	// The error of the action is recorded in the step and returned.
+	Execute(action AgentAction) (Step, error)
+	// Observe records the current state of the page as a step without an action, e.g. before the first action.
+	Observe() (Step, error)
+	// Transcript returns the transcript of the executed actions.
+	Transcript() *Transcript
+}
+
+// AgentOptions configures an agent session.
+type AgentOptions struct {
+	// Screenshots captures a screenshot after every step.
+	Screenshots bool
+	// MaxViewSize truncates the view of each step to the number of bytes, unlimited if zero.
+	MaxViewSize int
+}
+
+type agentSession struct {
+	c          *Puppet
+	opts       AgentOptions
+	transcript *Transcript
+}
+
+// NewAgentSession returns an ActionExecutor driving the Puppet for an agent.
+func (c *Puppet) NewAgentSession(opts AgentOptions) ActionExecutor {
+	return &agentSession{
+		c:          c,
+		opts:       opts,
+		transcript: &Transcript{},
+	}
+}
+
+func (a *agentSession) Transcript() *Transcript {
+	return a.transcript
+}
+
+func (a *agentSession) Execute(action AgentAction) (Step, error) {
+	c := a.c
+	var err error
+	switch action.Type {
+	case "navigate":
+		err = c.Navigate(action.URL)
+	case "click":
+		err = c.Click(action.Selector)
+	case "type":
+		err = c.SendKeys(action.Selector, action.Text)
+	case "set_value":
+		err = c.SetValue(action.Selector, action.Text)
+	case "submit":
+		err = c.Submit(action.Selector)
+	case "back":
+		err = c.NavigateBack()
+	case "forward":
+		err = c.NavigateForward()
+	case "reload":
+		err = c.Reload()
+	case "scroll_into_view":
+		err = c.ScrollIntoView(action.Selector)
+	case "wait_visible":
+		err = c.WaitVisible(action.Selector)
+	default:
+		err = fmt.Errorf("unknown action type %q", action.Type)
+	}
+	step := a.observe(action)
+	if err != nil {
+		step.Error = err.Error()
+	}
+	return a.transcript.add(step), err
+}
+
+func (a *agentSession) Observe() (Step, error) {
+	return a.transcript.add(a.observe(AgentAction{})), nil
+}
+
+// observe captures the state of the page after the action.
+func (a *agentSession) observe(action AgentAction) Step {
+	c := a.c
+	step := Step{
+		Time:   time.Now(),
+		Action: action,
+	}
+	step.URL, _ = c.Location()
+	step.Title, _ = c.Title()
+	view, err := c.SimplifiedView()
+	if err == nil {
+		step.View = view.String()
+	}
+	if a.opts.MaxViewSize > 0 && len(step.View) > a.opts.MaxViewSize {
+		step.View = step.View[:a.opts.MaxViewSize] + "\n..."
+	}
+	if a.opts.Screenshots {
+		step.Screenshot, _ = c.Screenshot()
+	}
+	return step
+}
+
+// ScreenshotDataURL returns the screenshot of the step as a data url, e.g. for the image input of a model.
+func (s Step) ScreenshotDataURL() string {
+	if len(s.Screenshot) == 0 {
+		return ""
+	}
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(s.Screenshot)
+}
@@ -0,0 +1,204 @@
+package puppet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ServeArchive serves the pages and resources of the MHTML, WARC or gzip compressed WARC archive on the mux,
+// with the links between them rewritten to the local server, so captured pages can be rendered offline.
+// The main document of the archive is served at "/", and a resource at "/<host>/<path>".
+// The root-relative urls of the documents are rewritten to their hosts, and the root-relative requests
+// made by scripts are resolved against the host of the document referring them.
+func ServeArchive(archive io.Reader, mux *http.ServeMux) (err error) {
+	read := bufio.NewReader(archive)
+	if magic, err := read.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		// a .warc.gz is a series of gzip members, one per record
+		gz, err := gzip.NewReader(read)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		read = bufio.NewReader(gz)
+	}
+	head, _ := read.Peek(5)
+	var files []*file
+	if string(head) == "WARC/" {
+		files, err = warcFiles(read)
+	} else {
+		files, err = toFiles(read)
+	}
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("archive is empty")
+	}
+
+	// rewrite the longest urls first, so urls prefixed by others are rewritten whole
+	refs := map[string]string{}
+	for _, f := range files {
+		if f.Base != "" {
+			refs[f.Base] = archivePath(f.Base)
+		}
+		if f.ContentID != "" {
+			refs["cid:"+f.ContentID] = archivePath(f.Base)
+		}
+	}
+	olds := make([]string, 0, len(refs))
+	for old := range refs {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool {
+		return len(olds[i]) > len(olds[j])
+	})
+	pairs := make([]string, 0, len(olds)*4)
+	for _, old := range olds {
+		pairs = append(pairs, old, refs[old])
+		if escaped := html.EscapeString(old); escaped != old {
+			pairs = append(pairs, escaped, refs[old])
+		}
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	served := map[string]*file{}
+	for _, f := range files {
+		mediaType, _, _ := mime.ParseMediaType(f.ContentType)
+		if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "javascript") || strings.HasSuffix(mediaType, "+xml") {
+			data := string(f.Data)
+			if u, err := url.Parse(f.Base); err == nil && u.Host != "" && (mediaType == "text/html" || mediaType == "text/css" || strings.HasSuffix(mediaType, "+xml")) {
+				data = rootRelative.ReplaceAllString(data, "${1}/"+u.Host+"/${2}")
+			}
+			f.Data = []byte(replacer.Replace(data))
+		}
+		p := archivePath(f.Base)
+		if _, ok := served[p]; !ok {
+			served[p] = f
+		}
+	}
+	main := archivePath(files[0].Base)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		p := r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			p += "%3F" + url.PathEscape(r.URL.RawQuery)
+		}
+		if p == "/" {
+			http.Redirect(w, r, main, http.StatusFound)
+			return
+		}
+		f, ok := served[p]
+		if !ok {
+			// a root-relative request of a script, resolved against the host of the referring document
+			if ref, err := url.Parse(r.Referer()); err == nil && ref.Host == r.Host {
+				if parts := strings.SplitN(strings.TrimPrefix(ref.EscapedPath(), "/"), "/", 2); parts[0] != "" && parts[0] != "_" {
+					f, ok = served["/"+parts[0]+p]
+				}
+			}
+		}
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if f.ContentType != "" {
+			w.Header().Set("Content-Type", f.ContentType)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(f.Data)))
+		w.Write(f.Data)
+	})
+	return nil
+}
+
+// rootRelative matches the root-relative urls of the attributes and the style sheets, e.g. `src="/app.js"`
+// or `url(/logo.png)`, without the protocol-relative ones.
+var rootRelative = regexp.MustCompile(`(\b(?:src|href|action|poster|data)\s*=\s*["']?|url\(\s*["']?|@import\s+["'])/([^/]|$)`)
+
+// archivePath returns the local path the resource at the url is served at, e.g. "/example.com/index.html".
+func archivePath(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return "/_/" + url.PathEscape(rawurl)
+	}
+	p := "/" + u.Host + u.EscapedPath()
+	if u.Path == "" {
+		p += "/"
+	}
+	if u.RawQuery != "" {
+		p += "%3F" + url.PathEscape(u.RawQuery)
+	}
+	return p
+}
+
+// warcFiles reads the response records of the WARC archive.
+func warcFiles(read *bufio.Reader) (files []*file, err error) {
+	tp := textproto.NewReader(read)
+	for {
+		version, err := tp.ReadLine()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if version == "" {
+			continue
+		}
+		if !strings.HasPrefix(version, "WARC/") {
+			return nil, fmt.Errorf("malformed WARC record %q", version)
+		}
+		hdr, err := tp.ReadMIMEHeader()
+		if err != nil {
+			return nil, err
+		}
+		length, err := strconv.ParseInt(hdr.Get("Content-Length"), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		block, err := ioutil.ReadAll(io.LimitReader(read, length))
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Get("WARC-Type") != "response" || !strings.HasPrefix(hdr.Get("Content-Type"), "application/http") {
+			continue
+		}
+		target := hdr.Get("WARC-Target-URI")
+		resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(block)), nil)
+		if err != nil {
+			continue
+		}
+		var body io.Reader = resp.Body
+		if resp.Header.Get("Content-Encoding") == "gzip" {
+			body, err = gzip.NewReader(resp.Body)
+			if err != nil {
+				resp.Body.Close()
+				continue
+			}
+		}
+		data, err := ioutil.ReadAll(body)
+		resp.Body.Close()
+		if err != nil {
+			continue
+		}
+		if resp.StatusCode/100 == 3 {
+			continue
+		}
+		files = append(files, &file{
+			ContentType: resp.Header.Get("Content-Type"),
+			Base:        strings.Trim(target, "<>"),
+			Data:        data,
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package puppet
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ArtifactStore stores the artifacts produced by the Puppet, e.g. screenshots, page sources and reports.
+type ArtifactStore interface {
+	// Put stores the data under the name, and returns the location it can be retrieved from.
+	Put(name string, contentType string, data []byte) (location string, err error)
+}
+
+// DirStore is an ArtifactStore that stores the artifacts as files in a local directory.
+type DirStore string
+
+// Put stores the data in the file with the name below the directory.
+func (d DirStore) Put(name string, contentType string, data []byte) (location string, err error) {
+	file := filepath.Join(string(d), filepath.FromSlash(path.Clean("/"+name)))
+	err = os.MkdirAll(filepath.Dir(file), 0755)
+	if err != nil {
+		return "", err
+	}
+	err = ioutil.WriteFile(file, data, 0644)
+	if err != nil {
+		return "", err
+	}
+	return file, nil
+}
+
+// S3Store is an ArtifactStore that stores the artifacts as objects in an S3 compatible bucket.
+type S3Store struct {
+	// Endpoint is the url of the S3 service, e.g. "https://s3.us-east-1.amazonaws.com".
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is prepended to the names of the artifacts.
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// Client is the HTTP client used, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Put uploads the data as the object with the name, and returns the url of the object.
+func (s *S3Store) Put(name string, contentType string, data []byte) (location string, err error) {
+	key := strings.TrimPrefix(path.Join(s.Prefix, name), "/")
+	u, err := url.Parse(strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + key)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.sign(req, data, time.Now().UTC())
+
+	cli := s.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("put %s: %s: %s", u, resp.Status, msg)
+	}
+	return u.String(), nil
+}
+
+// sign signs the request with the AWS signature version 4.
+func (s *S3Store) sign(req *http.Request, payload []byte, now time.Time) {
+	const algorithm = "AWS4-HMAC-SHA256"
+	date := now.Format("20060102")
+	stamp := now.Format("20060102T150405Z")
+	sum := sha256.Sum256(payload)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// all the headers set are signed, the Host header is sent from the url
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, strings.ToLower(name))
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		var values []string
+		for _, v := range req.Header.Values(name) {
+			values = append(values, strings.Join(strings.Fields(v), " "))
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// the path is signed as sent, so it is sent encoded as required by the signature
+	canonicalPath := uriEncode(req.URL.Path, false)
+	req.URL.RawPath = canonicalPath
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	crSum := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		algorithm,
+		stamp,
+		scope,
+		hex.EncodeToString(crSum[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.AccessKey, scope, signedHeaders, signature))
+}
+
+// uriEncode encodes s as the UriEncode of the AWS signature version 4, all the bytes but the unreserved characters
+// are percent encoded with upper case hex digits, and the slashes are kept unless encodeSlash is set.
+func uriEncode(s string, encodeSlash bool) string {
+	const digits = "0123456789ABCDEF"
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '.', b == '_', b == '~',
+			b == '/' && !encodeSlash:
+			sb.WriteByte(b)
+		default:
+			sb.WriteByte('%')
+			sb.WriteByte(digits[b>>4])
+			sb.WriteByte(digits[b&15])
+		}
+	}
+	return sb.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,121 @@
+package puppet
+
+import (
+	"context"
+	"sync"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+)
+
+// BandwidthMeter accounts the bytes transferred by jobs, it can be shared by the targets of
+// several Puppets, so the caps hold across all of them, e.g. to stay under a proxy bandwidth budget.
+type BandwidthMeter struct {
+	mu    sync.Mutex
+	used  map[string]int64
+	caps  map[string]int64
+	total int64
+}
+
+// NewBandwidthMeter creates a new BandwidthMeter without caps.
+func NewBandwidthMeter() *BandwidthMeter {
+	return &BandwidthMeter{
+		used: map[string]int64{},
+		caps: map[string]int64{},
+	}
+}
+
+// SetCap caps the bytes transferred by the job, the empty job caps the bytes transferred by all jobs,
+// a cap of zero or less removes the cap. Requests of a job over its cap fail.
+func (m *BandwidthMeter) SetCap(job string, max int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max <= 0 {
+		delete(m.caps, job)
+		return
+	}
+	m.caps[job] = max
+}
+
+// Used returns the bytes transferred by the job, the empty job returns the bytes transferred by all jobs.
+func (m *BandwidthMeter) Used(job string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job == "" {
+		return m.total
+	}
+	return m.used[job]
+}
+
+// Jobs returns the bytes transferred by every job.
+func (m *BandwidthMeter) Jobs() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res := make(map[string]int64, len(m.used))
+	for job, n := range m.used {
+		res[job] = n
+	}
+	return res
+}
+
+// Exceeded reports whether the job or all jobs together reached their cap.
+func (m *BandwidthMeter) Exceeded(job string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if max, ok := m.caps[""]; ok && m.total >= max {
+		return true
+	}
+	if max, ok := m.caps[job]; ok && job != "" && m.used[job] >= max {
+		return true
+	}
+	return false
+}
+
+// Reset forgets the bytes transferred by the job, the empty job resets all jobs.
+func (m *BandwidthMeter) Reset(job string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job == "" {
+		m.used = map[string]int64{}
+		m.total = 0
+		return
+	}
+	m.total -= m.used[job]
+	delete(m.used, job)
+}
+
+func (m *BandwidthMeter) add(job string, n int64) {
+	m.mu.Lock()
+	m.used[job] += n
+	m.total += n
+	m.mu.Unlock()
+}
+
+// MeterBandwidth accounts the bytes transferred by the current target to the job of the meter,
+// and fails its requests once the job or all jobs together reached their cap.
+func (c *Puppet) MeterBandwidth(m *BandwidthMeter, job string) (err error) {
+	err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *network.EventLoadingFinished:
+			m.add(job, int64(ev.EncodedDataLength))
+		case *network.EventWebSocketFrameReceived:
+			m.add(job, int64(len(ev.Response.PayloadData)))
+		case *network.EventWebSocketFrameSent:
+			m.add(job, int64(len(ev.Response.PayloadData)))
+		}
+	},
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkWebSocketFrameReceived,
+		cdproto.EventNetworkWebSocketFrameSent,
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.intercept(nil, false, func(r *Request) {
+		if m.Exceeded(job) {
+			r.Fail()
+		}
+	})
+}
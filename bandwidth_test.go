@@ -0,0 +1,33 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestMeterBandwidth(t *testing.T) {
+	p, b := newFakePuppet(t)
+	m := NewBandwidthMeter()
+	m.SetCap("job", 150)
+	err := p.MeterBandwidth(m, "job")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Network.loadingFinished", `{"requestId":"1","encodedDataLength":100}`)
+	b.emit("Network.webSocketFrameReceived", `{"requestId":"2","response":{"opcode":1,"mask":false,"payloadData":"0123456789"}}`)
+	eventually(t, func() bool {
+		return m.Used("job") == 110
+	})
+	if m.Exceeded("job") {
+		t.Fatal("cap exceeded at 110 bytes")
+	}
+
+	b.emit("Network.loadingFinished", `{"requestId":"3","encodedDataLength":50}`)
+	eventually(t, func() bool {
+		return m.Exceeded("job")
+	})
+	b.emit("Fetch.requestPaused", `{"requestId":"r4","networkId":"4","frameId":"F","resourceType":"Image","request":{"url":"https://example.com/a.png","method":"GET","headers":{}}}`)
+	eventually(t, func() bool {
+		return b.called("Fetch.failRequest") == 1
+	})
+}
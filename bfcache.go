@@ -0,0 +1,87 @@
+package puppet
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/chromedp"
+)
+
+// eventPageBackForwardCacheNotUsed is fired when a page was not restored from the back/forward cache,
+// it is unknown to cdproto.
+const eventPageBackForwardCacheNotUsed cdproto.MethodType = "Page.backForwardCacheNotUsed"
+
+// backForwardCacheNotUsed is the event of eventPageBackForwardCacheNotUsed.
+type backForwardCacheNotUsed struct {
+	NotRestoredExplanations []struct {
+		Reason string `json:"reason"`
+	} `json:"notRestoredExplanations"`
+}
+
+// BFCacheResult is the outcome of a back navigation.
+type BFCacheResult struct {
+	// Restored reports whether the page was restored from the back/forward cache.
+	Restored bool
+	// Reasons are the reasons the page was not restored from the back/forward cache.
+	Reasons []string
+}
+
+// TestBFCache navigates the current target to the url and back again,
+// and reports whether the page was restored from the back/forward cache and why not.
+func (c *Puppet) TestBFCache(url string) (res *BFCacheResult, err error) {
+	marker := fmt.Sprintf("%d", rand.Int63())
+	var ok bool
+	err = c.run(c.ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(window.__puppetBFCache = %q, true)`, marker), &ok))
+	if err != nil {
+		return nil, err
+	}
+
+	ch, release, err := c.subscribe(eventPageBackForwardCacheNotUsed)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	err = c.Navigate(url)
+	if err != nil {
+		return nil, err
+	}
+	err = c.NavigateBack()
+	if err != nil {
+		return nil, err
+	}
+
+	res = &BFCacheResult{}
+	var got string
+	err = c.run(c.ctx,
+		chromedp.Evaluate(`String(window.__puppetBFCache)`, &got))
+	if err != nil {
+		return nil, err
+	}
+	res.Restored = got == marker
+
+	if !res.Restored {
+		// the reasons may arrive slightly after the navigation has completed
+		timeout := time.After(time.Second)
+	loop:
+		for {
+			select {
+			case ev := <-ch:
+				notUsed, ok := ev.(*backForwardCacheNotUsed)
+				if !ok {
+					continue
+				}
+				for _, e := range notUsed.NotRestoredExplanations {
+					res.Reasons = append(res.Reasons, e.Reason)
+				}
+				break loop
+			case <-timeout:
+				break loop
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,390 @@
+package puppet
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+)
+
+// Blocker blocks requests matching network filter rules in the EasyList / uBlock Origin syntax,
+// e.g. "||ads.example.com^$third-party" or "@@||example.com/ads.js$script".
+// Cosmetic rules are ignored.
+type Blocker struct {
+	mu         sync.RWMutex
+	hosts      map[string][]*filterRule
+	rules      []*filterRule
+	exceptions []*filterRule
+}
+
+type filterRule struct {
+	re         *regexp.Regexp
+	types      map[ResourceType]bool
+	notTypes   map[ResourceType]bool
+	thirdParty int // 1 third-party only, -1 first-party only
+	domains    []string
+	notDomains []string
+}
+
+// NewBlocker creates a new Blocker without rules.
+func NewBlocker() *Blocker {
+	return &Blocker{
+		hosts: map[string][]*filterRule{},
+	}
+}
+
+// AddRules adds the rules of the filter list read from r, one rule per line.
+func (b *Blocker) AddRules(r io.Reader) (err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		b.AddRule(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// AddRule adds the rule, unsupported rules are ignored.
+func (b *Blocker) AddRule(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || line[0] == '!' || line[0] == '[' ||
+		strings.Contains(line, "##") || strings.Contains(line, "#@#") || strings.Contains(line, "#?#") || strings.Contains(line, "#$#") {
+		return
+	}
+
+	exception := strings.HasPrefix(line, "@@")
+	if exception {
+		line = line[2:]
+	}
+
+	rule := &filterRule{}
+	pattern := line
+	matchCase := false
+	regexpRule := len(line) > 1 && line[0] == '/' && line[len(line)-1] == '/'
+	if i := strings.LastIndexByte(line, '$'); i >= 0 && !regexpRule {
+		pattern = line[:i]
+		for _, opt := range strings.Split(line[i+1:], ",") {
+			not := strings.HasPrefix(opt, "~")
+			opt = strings.TrimPrefix(opt, "~")
+			switch {
+			case opt == "third-party" || opt == "3p":
+				rule.thirdParty = 1
+				if not {
+					rule.thirdParty = -1
+				}
+			case opt == "first-party" || opt == "1p":
+				rule.thirdParty = -1
+				if not {
+					rule.thirdParty = 1
+				}
+			case opt == "match-case":
+				matchCase = true
+			case strings.HasPrefix(opt, "domain="):
+				for _, d := range strings.Split(opt[len("domain="):], "|") {
+					if strings.HasPrefix(d, "~") {
+						rule.notDomains = append(rule.notDomains, d[1:])
+					} else {
+						rule.domains = append(rule.domains, d)
+					}
+				}
+			default:
+				types, ok := filterTypes[opt]
+				if !ok {
+					// options not supported change the meaning of the rule, so skip it
+					return
+				}
+				if not {
+					if rule.notTypes == nil {
+						rule.notTypes = map[ResourceType]bool{}
+					}
+					for _, t := range types {
+						rule.notTypes[t] = true
+					}
+				} else {
+					if rule.types == nil {
+						rule.types = map[ResourceType]bool{}
+					}
+					for _, t := range types {
+						rule.types[t] = true
+					}
+				}
+			}
+		}
+	}
+
+	re, host := filterRegexp(pattern)
+	if !matchCase {
+		re = "(?i)" + re
+	}
+	var err error
+	rule.re, err = regexp.Compile(re)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case exception:
+		b.exceptions = append(b.exceptions, rule)
+	case host != "":
+		b.hosts[host] = append(b.hosts[host], rule)
+	default:
+		b.rules = append(b.rules, rule)
+	}
+}
+
+var filterTypes = map[string][]ResourceType{
+	"script":         {ResourceScript},
+	"image":          {ResourceImage},
+	"stylesheet":     {ResourceStylesheet},
+	"font":           {ResourceFont},
+	"media":          {ResourceMedia},
+	"xmlhttprequest": {ResourceXHR, ResourceFetch},
+	"xhr":            {ResourceXHR, ResourceFetch},
+	"document":       {ResourceDocument},
+	"doc":            {ResourceDocument},
+	"subdocument":    {ResourceSubdocument},
+	"frame":          {ResourceSubdocument},
+	"websocket":      {ResourceWebSocket},
+	"other":          {ResourceOther},
+	"ping":           {ResourceOther},
+}
+
+// filterRegexp converts the filter pattern to a regular expression,
+// and returns the host if the pattern blocks a whole host, e.g. "||ads.example.com^".
+func filterRegexp(pattern string) (re string, host string) {
+	if len(pattern) > 1 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+		return pattern[1 : len(pattern)-1], ""
+	}
+
+	var sb strings.Builder
+	switch {
+	case strings.HasPrefix(pattern, "||"):
+		pattern = pattern[2:]
+		sb.WriteString(`^[a-z][a-z0-9+.-]*://([^/?#]*\.)?`)
+		if i := strings.IndexAny(pattern, "^/*|"); i > 0 && (pattern[i:] == "^" || pattern[i:] == "^|") {
+			host = strings.ToLower(pattern[:i])
+		}
+	case strings.HasPrefix(pattern, "|"):
+		pattern = pattern[1:]
+		sb.WriteString("^")
+	}
+	end := strings.HasSuffix(pattern, "|")
+	if end {
+		pattern = pattern[:len(pattern)-1]
+	}
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '^':
+			sb.WriteString(`(?:[^a-zA-Z0-9_.%-]|$)`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if end {
+		sb.WriteString("$")
+	}
+	return sb.String(), host
+}
+
+// Match reports whether the request of the resource type to the url from the document url is blocked.
+func (b *Blocker) Match(rawurl string, documentURL string, typ ResourceType) bool {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	docHost := ""
+	if d, err := url.Parse(documentURL); err == nil {
+		docHost = strings.ToLower(d.Hostname())
+	}
+	thirdParty := docHost != "" && siteOf(rawurl) != siteOf(documentURL)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	matched := false
+	for h := host; h != "" && !matched; {
+		for _, rule := range b.hosts[h] {
+			if rule.match(rawurl, docHost, thirdParty, typ) {
+				matched = true
+				break
+			}
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	for _, rule := range b.rules {
+		if matched {
+			break
+		}
+		matched = rule.match(rawurl, docHost, thirdParty, typ)
+	}
+	if !matched {
+		return false
+	}
+	for _, rule := range b.exceptions {
+		if rule.match(rawurl, docHost, thirdParty, typ) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *filterRule) match(rawurl string, docHost string, thirdParty bool, typ ResourceType) bool {
+	if r.types != nil && !r.types[typ] {
+		return false
+	}
+	if r.notTypes[typ] {
+		return false
+	}
+	if (r.thirdParty == 1 && !thirdParty) || (r.thirdParty == -1 && thirdParty) {
+		return false
+	}
+	if len(r.domains) != 0 && !hostInDomains(docHost, r.domains) {
+		return false
+	}
+	if hostInDomains(docHost, r.notDomains) {
+		return false
+	}
+	return r.re.MatchString(rawurl)
+}
+
+func hostInDomains(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceSubdocument is the type of the documents loaded into frames for Blocker.Match,
+// as the filter rules tell them apart from the top level documents, which the browser does not.
+const ResourceSubdocument ResourceType = "Subdocument"
+
+// UseBlocker fails all requests of the current target blocked by the blocker.
+func (c *Puppet) UseBlocker(b *Blocker) (err error) {
+	frames := &frameURLs{
+		frames: map[string]*frameDocument{},
+	}
+	err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		frames.update(ev)
+	},
+		cdproto.EventPageFrameAttached,
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventPageFrameDetached,
+	)
+	if err != nil {
+		return err
+	}
+	return c.intercept(nil, false, func(r *Request) {
+		doc, typ := frames.requestDocument(r)
+		if b.Match(r.URL, doc, typ) {
+			r.Fail()
+		}
+	})
+}
+
+// frameURLs are the urls of the documents of the frames of a target, kept by the frame events
+// so the document of every request is found without a round trip.
+type frameURLs struct {
+	mu     sync.Mutex
+	frames map[string]*frameDocument
+}
+
+type frameDocument struct {
+	url    string
+	parent string
+}
+
+// update updates the documents by the frame event.
+func (f *frameURLs) update(ev interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch ev := ev.(type) {
+	case *page.EventFrameAttached:
+		f.frames[string(ev.FrameID)] = &frameDocument{
+			parent: string(ev.ParentFrameID),
+		}
+	case *page.EventFrameNavigated:
+		f.frames[string(ev.Frame.ID)] = &frameDocument{
+			url:    ev.Frame.URL,
+			parent: string(ev.Frame.ParentID),
+		}
+	case *page.EventFrameDetached:
+		delete(f.frames, string(ev.FrameID))
+	}
+}
+
+// load loads the documents of the frame tree, for the frames attached before the events were listened to.
+func (f *frameURLs) load(r *Request) error {
+	tree, err := page.GetFrameTree().
+		Do(r.ctx, r.h)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var walk func(t *page.FrameTree)
+	walk = func(t *page.FrameTree) {
+		f.frames[string(t.Frame.ID)] = &frameDocument{
+			url:    t.Frame.URL,
+			parent: string(t.Frame.ParentID),
+		}
+		for _, child := range t.ChildFrames {
+			walk(child)
+		}
+	}
+	walk(tree)
+	return nil
+}
+
+func (f *frameURLs) get(id string) (doc frameDocument, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d, ok := f.frames[id]
+	if !ok {
+		return doc, false
+	}
+	return *d, true
+}
+
+// requestDocument returns the url of the document making the request, and the type of the request
+// telling the subdocuments apart. The document of a navigation is the one of the parent frame, if any,
+// and the document of a top level navigation is the one navigated to.
+func (f *frameURLs) requestDocument(r *Request) (doc string, typ ResourceType) {
+	typ = r.ResourceType
+	frame, ok := f.get(r.FrameID)
+	if !ok && r.FrameID != "" {
+		// the frame events may be behind the request
+		if f.load(r) != nil {
+			return r.Header.Get("Referer"), typ
+		}
+		frame, ok = f.get(r.FrameID)
+	}
+	switch {
+	case !ok:
+		return r.Header.Get("Referer"), typ
+	case !r.IsNavigation:
+		return frame.url, typ
+	case frame.parent == "":
+		return r.URL, typ
+	}
+	parent, ok := f.get(frame.parent)
+	if !ok {
+		return r.Header.Get("Referer"), ResourceSubdocument
+	}
+	return parent.url, ResourceSubdocument
+}
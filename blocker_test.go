@@ -0,0 +1,61 @@
+package puppet
+
+import (
+	"strings"
+	"testing"
+)
+
+const testFilters = `! comment
+[Adblock Plus 2.0]
+||ads.example.com^
+/banner/*$image
+||tracker.com^$third-party
+@@||ads.example.com/allowed.js$script
+||cdn.example.com^$domain=news.com|~sports.news.com
+||frame.com^$subdocument
+/ad[0-9]+\.js/
+example.com##.ad
+||unsupported.com^$popup
+`
+
+func TestBlockerMatch(t *testing.T) {
+	b := NewBlocker()
+	err := b.AddRules(strings.NewReader(testFilters))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		rawurl      string
+		documentURL string
+		typ         ResourceType
+		want        bool
+	}{
+		{"https://ads.example.com/x.js", "https://site.com/", ResourceScript, true},
+		{"https://sub.ads.example.com/x.png", "https://site.com/", ResourceImage, true},
+		{"https://notads.example.com/x.js", "https://site.com/", ResourceScript, false},
+		{"https://ads.example.com/allowed.js", "https://site.com/", ResourceScript, false},
+		{"https://ads.example.com/allowed.js", "https://site.com/", ResourceImage, true},
+		{"https://site.com/banner/1.png", "https://site.com/", ResourceImage, true},
+		{"https://site.com/banner/1.js", "https://site.com/", ResourceScript, false},
+		{"https://tracker.com/pixel", "https://site.com/", ResourceImage, true},
+		{"https://tracker.com/pixel", "https://tracker.com/", ResourceImage, false},
+		{"https://www.tracker.com/pixel", "https://tracker.com/", ResourceImage, false},
+		{"https://cdn.example.com/lib.js", "https://news.com/", ResourceScript, true},
+		{"https://cdn.example.com/lib.js", "https://www.news.com/", ResourceScript, true},
+		{"https://cdn.example.com/lib.js", "https://sports.news.com/", ResourceScript, false},
+		{"https://cdn.example.com/lib.js", "https://other.com/", ResourceScript, false},
+		{"https://frame.com/embed", "https://site.com/", ResourceSubdocument, true},
+		{"https://frame.com/embed", "https://site.com/", ResourceDocument, false},
+		{"https://site.com/ad12.js", "https://site.com/", ResourceScript, true},
+		{"https://site.com/AD12.JS", "https://site.com/", ResourceScript, true},
+		{"https://example.com/.ad", "https://example.com/", ResourceOther, false},
+		{"https://unsupported.com/", "https://site.com/", ResourceDocument, false},
+		{"://bad", "https://site.com/", ResourceScript, false},
+	}
+	for _, tt := range tests {
+		if got := b.Match(tt.rawurl, tt.documentURL, tt.typ); got != tt.want {
+			t.Errorf("Match(%q, %q, %s) = %v, want %v", tt.rawurl, tt.documentURL, tt.typ, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/mailru/easyjson"
+)
+
+// CapturedRequest is a request observed during a session, it can be modified and re-issued by Replay
+// or shared as a curl command by AsCurl.
+type CapturedRequest struct {
+	URL    string
+	Method string
+	// Header is the header of the request, including the cookies of the browser for the url.
+	Header       http.Header
+	PostData     string
+	ResourceType ResourceType
+	Time         time.Time
+}
+
+// CaptureRequests calls fn with every request of the current target with the url matching the pattern,
+// the pattern is a glob where "*" matches any characters, an empty pattern matches all requests.
+func (c *Puppet) CaptureRequests(pattern string, fn func(*CapturedRequest)) (err error) {
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		e, ok := ev.(*requestWillBeSent)
+		if !ok {
+			return
+		}
+		u := e.Request.URL + e.Request.URLFragment
+		if pattern != "" && !globRegexp(pattern).MatchString(u) {
+			return
+		}
+		r := &CapturedRequest{
+			URL:          u,
+			Method:       e.Request.Method,
+			Header:       http.Header{},
+			PostData:     requestPostData(e.Request, e.Body),
+			ResourceType: e.Type,
+			Time:         time.Now(),
+		}
+		for k, v := range e.Request.Headers {
+			if s, ok := v.(string); ok {
+				r.Header.Set(k, s)
+			}
+		}
+		cookies, err := network.GetCookies().
+			WithUrls([]string{u}).
+			Do(ctx, h)
+		if err == nil && len(cookies) != 0 {
+			pairs := make([]string, 0, len(cookies))
+			for _, cookie := range cookies {
+				pairs = append(pairs, cookie.Name+"="+cookie.Value)
+			}
+			r.Header.Set("Cookie", strings.Join(pairs, "; "))
+		}
+		fn(r)
+	},
+		cdproto.EventNetworkRequestWillBeSent,
+	)
+}
+
+// requestWillBeSent is the Network.requestWillBeSent event, with the fields cdproto lacks.
+type requestWillBeSent struct {
+	network.EventRequestWillBeSent
+	Body requestBody
+}
+
+func (e *requestWillBeSent) UnmarshalJSON(data []byte) error {
+	err := easyjson.Unmarshal(data, &e.EventRequestWillBeSent)
+	if err != nil {
+		return err
+	}
+	var extra struct {
+		Request requestBody `json:"request"`
+	}
+	err = json.Unmarshal(data, &extra)
+	if err != nil {
+		return err
+	}
+	e.Body = extra.Request
+	return nil
+}
+
+// Capture returns the intercepted request as a captured request, with its body loaded if left out by the browser.
+func (r *Request) Capture() *CapturedRequest {
+	r.LoadPostData()
+	header := http.Header{}
+	for k, vs := range r.Header {
+		header[k] = append([]string(nil), vs...)
+	}
+	return &CapturedRequest{
+		URL:          r.URL,
+		Method:       r.Method,
+		Header:       header,
+		PostData:     r.PostData,
+		ResourceType: r.ResourceType,
+		Time:         time.Now(),
+	}
+}
+
+// Clone returns a copy of the request, to be modified before Replay.
+func (r *CapturedRequest) Clone() *CapturedRequest {
+	n := *r
+	n.Header = http.Header{}
+	for k, vs := range r.Header {
+		n.Header[k] = append([]string(nil), vs...)
+	}
+	return &n
+}
+
+// Replay issues the request again with the client, or with http.DefaultClient if nil.
+// The caller must close the body of the response.
+func (r *CapturedRequest) Replay(ctx context.Context, cli *http.Client) (*http.Response, error) {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(r.PostData))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, vs := range r.Header {
+		req.Header[k] = vs
+	}
+	// let the transport negotiate and decode the compression
+	req.Header.Del("Accept-Encoding")
+	return cli.Do(req)
+}
+
+// AsCurl returns the request as a curl command line for a POSIX shell.
+func (r *CapturedRequest) AsCurl() string {
+	var sb strings.Builder
+	sb.WriteString("curl")
+	if r.Method != "" && r.Method != http.MethodGet && !(r.Method == http.MethodPost && r.PostData != "") {
+		sb.WriteString(" -X ")
+		sb.WriteString(r.Method)
+	}
+	sb.WriteString(" ")
+	sb.WriteString(shellQuote(r.URL))
+
+	keys := make([]string, 0, len(r.Header))
+	for k := range r.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	compressed := false
+	for _, k := range keys {
+		if strings.EqualFold(k, "Accept-Encoding") {
+			compressed = true
+			continue
+		}
+		for _, v := range r.Header[k] {
+			sb.WriteString(" \\\n  -H ")
+			sb.WriteString(shellQuote(k + ": " + v))
+		}
+	}
+	if r.PostData != "" {
+		sb.WriteString(" \\\n  --data-raw ")
+		sb.WriteString(shellQuote(r.PostData))
+	}
+	if compressed {
+		sb.WriteString(" \\\n  --compressed")
+	}
+	return sb.String()
+}
+
+// shellQuote quotes the string for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
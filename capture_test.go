@@ -0,0 +1,31 @@
+package puppet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCaptureRequests(t *testing.T) {
+	p, b := newFakePuppet(t)
+	captured := make(chan *CapturedRequest, 1)
+	err := p.CaptureRequests("https://example.com/api/*", func(r *CapturedRequest) {
+		captured <- r
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Network.requestWillBeSent", `{"requestId":"1","loaderId":"L","documentURL":"https://example.com/","type":"Image","request":{"url":"https://example.com/a.png","method":"GET","headers":{}}}`)
+	// the body is decoded from the entries, so binary bodies are kept
+	b.emit("Network.requestWillBeSent", `{"requestId":"2","loaderId":"L","documentURL":"https://example.com/","type":"Fetch","request":{"url":"https://example.com/api/items","method":"POST","headers":{"Content-Type":"application/octet-stream"},"postData":"garbled","hasPostData":true,"postDataEntries":[{"bytes":"AP8="}]}}`)
+
+	var r *CapturedRequest
+	select {
+	case r = <-captured:
+	case <-time.After(time.Second):
+		t.Fatal("request not captured in time")
+	}
+	if r.URL != "https://example.com/api/items" || r.Method != "POST" || r.PostData != "\x00\xff" || r.Header.Get("Content-Type") != "application/octet-stream" {
+		t.Errorf("got request %+v", r)
+	}
+}
@@ -0,0 +1,62 @@
+package puppet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// SetClipboard writes the text to the system clipboard through the current page.
+func (c *Puppet) SetClipboard(text string) (err error) {
+	err = c.grantClipboard()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(text)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	return c.run(c.ctx,
+		chromedp.Evaluate(fmt.Sprintf(`navigator.clipboard.writeText(%s).then(function () { return true })`, data), &ok, awaitPromise))
+}
+
+// Clipboard reads the text from the system clipboard through the current page.
+func (c *Puppet) Clipboard() (text string, err error) {
+	err = c.grantClipboard()
+	if err != nil {
+		return "", err
+	}
+	return text, c.run(c.ctx,
+		chromedp.Evaluate(`navigator.clipboard.readText()`, &text, awaitPromise))
+}
+
+// grantClipboard grants the clipboard permissions to the origin of the current page and focuses it,
+// the asynchronous clipboard API is only available to focused documents.
+func (c *Puppet) grantClipboard() (err error) {
+	var origin string
+	err = c.run(c.ctx,
+		chromedp.Evaluate(`location.origin`, &origin))
+	if err != nil {
+		return err
+	}
+	// granting replaces the permissions of the origin, so the ones already granted are kept
+	perms := c.Overrides().Permissions[origin]
+	for _, perm := range []Permission{PermissionClipboardRead, PermissionClipboardWrite} {
+		granted := false
+		for _, p := range perms {
+			granted = granted || p == perm
+		}
+		if !granted {
+			perms = append(perms, perm)
+		}
+	}
+	err = c.GrantPermissions(origin, perms...)
+	if err != nil {
+		return err
+	}
+	return c.run(c.ctx,
+		page.BringToFront())
+}
@@ -0,0 +1,31 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestClipboardKeepsPermissions(t *testing.T) {
+	p, b := newFakePuppet(t)
+	b.mu.Lock()
+	b.results["Runtime.evaluate"] = `{"result":{"type":"string","value":"https://example.com"}}`
+	b.mu.Unlock()
+
+	err := p.GrantPermissions("https://example.com", PermissionNotifications)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := p.Clipboard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "https://example.com" {
+		t.Fatalf("got clipboard %q", text)
+	}
+	want := `{"origin":"https://example.com","permissions":["notifications","clipboardRead","clipboardWrite"]}`
+	if got := b.lastParams("Browser.grantPermissions"); got != want {
+		t.Fatalf("got params %s, want %s", got, want)
+	}
+	if b.called("Page.bringToFront") != 1 {
+		t.Fatal("page not focused")
+	}
+}
@@ -0,0 +1,140 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Condition is a condition of the current page, checked by IfVisible and Race.
+type Condition struct {
+	// expr is the javascript expression of the condition, formatted with the JSON of the arg if any.
+	expr string
+	arg  string
+	// selector reports whether the arg is a selector, which may be the name of a page object.
+	selector bool
+}
+
+// queryNode is the javascript function returning the first node matching a CSS or XPath selector.
+const queryNode = `function (sel) {
+	try {
+		if (sel[0] === "/" || sel[0] === "(") {
+			return document.evaluate(sel, document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+		}
+		return document.querySelector(sel);
+	} catch (e) {
+		return null;
+	}
+}`
+
+// Visible is the condition that a node matching the selector is visible.
+func Visible(sel string) Condition {
+	return Condition{arg: sel, selector: true, expr: `(function (el) {
+	if (!el) {
+		return false;
+	}
+	var style = window.getComputedStyle(el);
+	var rect = el.getBoundingClientRect();
+	return style.visibility !== "hidden" && style.display !== "none" && rect.width > 0 && rect.height > 0;
+})((` + queryNode + `)(%s))`}
+}
+
+// Present is the condition that a node matching the selector is in the document.
+func Present(sel string) Condition {
+	return Condition{arg: sel, selector: true, expr: `!!(` + queryNode + `)(%s)`}
+}
+
+// URLContains is the condition that the url of the page contains the substring.
+func URLContains(substr string) Condition {
+	return Condition{arg: substr, expr: `location.href.indexOf(%s) >= 0`}
+}
+
+// Expression is the condition that the javascript expression is truthy.
+func Expression(expression string) Condition {
+	return Condition{expr: expression}
+}
+
+// expression returns the javascript expression of the condition, resolving the names of the page object.
+func (c *Puppet) expression(cond Condition) (string, error) {
+	if cond.arg == "" {
+		return `!!(` + cond.expr + `)`, nil
+	}
+	arg := cond.arg
+	if cond.selector {
+		arg = c.selector(arg)
+	}
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(cond.expr, data), nil
+}
+
+// IfVisible calls then if a node matching the selector is visible right now, or else otherwise,
+// either may be nil.
+func (c *Puppet) IfVisible(sel string, then, otherwise func() error) (err error) {
+	expr, err := c.expression(Visible(sel))
+	if err != nil {
+		return err
+	}
+	var visible bool
+	err = c.run(c.logAttrs("selector", sel),
+		chromedp.Evaluate(expr, &visible))
+	if err != nil {
+		return err
+	}
+	switch {
+	case visible && then != nil:
+		return then()
+	case !visible && otherwise != nil:
+		return otherwise()
+	}
+	return nil
+}
+
+// Race waits until any of the conditions holds or the context is done, and returns the index of the first that does,
+// e.g. c.Race(ctx, Visible("#dashboard"), Visible("#otp")) to branch on which page appeared.
+func (c *Puppet) Race(ctx context.Context, conds ...Condition) (winner int, err error) {
+	exprs := make([]string, 0, len(conds))
+	for _, cond := range conds {
+		expr, err := c.expression(cond)
+		if err != nil {
+			return -1, err
+		}
+		exprs = append(exprs, "function () { return "+expr+"; }")
+	}
+	expr := `(function (conds) {
+	for (var i = 0; i < conds.length; i++) {
+		try {
+			if (conds[i]()) {
+				return i;
+			}
+		} catch (e) {
+		}
+	}
+	return -1;
+})([` + strings.Join(exprs, ",\n") + `])`
+
+	for {
+		winner = -1
+		err = c.run(c.ctx,
+			chromedp.Evaluate(expr, &winner))
+		if err != nil && !IsTransient(err) {
+			return -1, err
+		}
+		if winner >= 0 {
+			return winner, nil
+		}
+		select {
+		case <-c.ctx.Done():
+			return -1, c.ctx.Err()
+		case <-ctx.Done():
+			return -1, fmt.Errorf("race of %d conditions: %w", len(conds), ErrTimeout)
+		case <-time.After(time.Second / 10):
+		}
+	}
+}
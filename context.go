@@ -0,0 +1,177 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// ContextOptions are the defaults inherited by every target created in a browser context.
+type ContextOptions struct {
+	// Permissions are the permissions granted by origin.
+	Permissions map[string][]Permission
+	// Geolocation is the position reported to pages.
+	Geolocation *Geolocation
+	// Locale is the ICU style locale, e.g. "en_US".
+	Locale string
+	// UserAgent is the user agent reported to pages.
+	UserAgent string
+	// AcceptLanguage is the Accept-Language header and navigator.languages reported to pages.
+	AcceptLanguage string
+	// Headers are the extra HTTP headers sent with every request.
+	Headers map[string]interface{}
+}
+
+// Geolocation is a position on the earth.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+}
+
+// localeOverrideParams are the parameters of Emulation.setLocaleOverride, which cdproto lacks.
+type localeOverrideParams struct {
+	Locale string `json:"locale,omitempty"`
+}
+
+// NewContext creates a new isolated browser context, like an incognito profile,
+// the opts are applied to every target created in it with NewContextTarget.
+func (c *Puppet) NewContext(opts ContextOptions) (id string, err error) {
+	var contextID target.BrowserContextID
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		contextID, err = target.CreateBrowserContext().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		for origin, perms := range opts.Permissions {
+			err = browser.GrantPermissions(origin, perms).
+				WithBrowserContextID(contextID).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return "", err
+	}
+
+	id = string(contextID)
+	c.mu.Lock()
+	if c.contexts == nil {
+		c.contexts = map[string]*ContextOptions{}
+	}
+	c.contexts[id] = &opts
+	c.mu.Unlock()
+	return id, nil
+}
+
+// CloseContext closes the browser context with the specified id and all of its targets.
+func (c *Puppet) CloseContext(id string) (err error) {
+	err = c.run(c.ctx,
+		target.DisposeBrowserContext(target.BrowserContextID(id)))
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	delete(c.contexts, id)
+	delete(c.labels, "context/"+id)
+	c.mu.Unlock()
+	return nil
+}
+
+// NewContextTarget creates a new Chrome target in the browser context with the specified id,
+// and applies the defaults of the browser context to it.
+func (c *Puppet) NewContextTarget(contextID string, url string) (id string, err error) {
+	c.mu.Lock()
+	opts, ok := c.contexts[contextID]
+	c.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("browser context %q not found", contextID)
+	}
+	err = c.checkMaxTargets()
+	if err != nil {
+		return "", err
+	}
+
+	var targetID target.ID
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		targetID, err = target.CreateTarget("about:blank").
+			WithBrowserContextID(target.BrowserContextID(contextID)).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return "", err
+	}
+	id = string(targetID)
+	c.seeTarget(id)
+
+	actions := chromedp.Tasks{}
+	if len(opts.Headers) != 0 {
+		actions = append(actions, c.withNetwork(network.SetExtraHTTPHeaders(network.Headers(opts.Headers))))
+	}
+	if opts.UserAgent != "" || opts.AcceptLanguage != "" {
+		actions = append(actions, userAgentOverride(opts.UserAgent, opts.AcceptLanguage))
+	}
+	if opts.Locale != "" {
+		params := &localeOverrideParams{
+			Locale: opts.Locale,
+		}
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			return execute(ctx, h, "Emulation.setLocaleOverride", params, nil)
+		}))
+	}
+	if opts.Geolocation != nil {
+		actions = append(actions, emulation.SetGeolocationOverride().
+			WithLatitude(opts.Geolocation.Latitude).
+			WithLongitude(opts.Geolocation.Longitude).
+			WithAccuracy(opts.Geolocation.Accuracy))
+	}
+	if url != "" {
+		actions = append(actions, c.navigate(url), waitComplete)
+	}
+
+	err = c.runOn(id, actions)
+	if err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// runOn runs the actions against the target with the specified id without changing the active target.
+func (c *Puppet) runOn(id string, actions ...chromedp.Action) (err error) {
+	h, err := c.handler(id)
+	if err != nil {
+		return err
+	}
+	for _, action := range actions {
+		err = action.Do(c.ctx, h)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handler waits until the target with the specified id is managed, and returns its handler.
+func (c *Puppet) handler(id string) (h cdp.Executor, err error) {
+	for i := 0; i != 50; i++ {
+		for _, t := range c.cdp.ListTargets() {
+			if t == id {
+				return c.cdp.GetHandlerByID(id), nil
+			}
+		}
+		time.Sleep(time.Second / 10)
+	}
+	return nil, &actionError{kind: ErrTargetNotFound, err: fmt.Errorf("target %q not found", id)}
+}
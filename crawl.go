@@ -0,0 +1,339 @@
+package puppet
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSkipLinks is returned by the page callback of a crawl to not follow the links of the page.
+var ErrSkipLinks = errors.New("skip links")
+
+// CrawlOptions configures Crawl.
+type CrawlOptions struct {
+	// MaxDepth is the maximum number of links followed from the start url, unlimited if zero.
+	MaxDepth int
+	// MaxPages is the maximum number of pages visited, unlimited if zero.
+	MaxPages int
+	// Concurrency is the number of pages visited concurrently, each in its own target, 1 if zero.
+	Concurrency int
+	// Allow and Deny are the patterns of the urls followed, as for SetNavigationPolicy,
+	// the links to other sites than the one of the start url are followed only if allowed explicitly.
+	Allow []string
+	Deny  []string
+	// Seeds are more urls visited as the start url, e.g. pages not linked from the start url.
+	Seeds []string
+	// Sitemaps are the urls of the sitemaps read by ReadSitemap, their urls are visited as the start url.
+	Sitemaps []string
+	// Links is the selector of the elements with the href of the links followed, "a[href]" if empty.
+	Links string
+	// Politeness honors the robots.txt and limits the rate of the requests per host,
+	// NewPoliteness() if nil unless Impolite is set.
+	Politeness *Politeness
+	// Impolite disables the politeness, e.g. to crawl a site under test.
+	Impolite bool
+	// Store is the frontier and the results of the crawl, NewMemoryCrawlStore() if nil.
+	Store CrawlStore
+	// OnPage is called with every page visited once it has loaded, the page can be inspected
+	// through its Puppet until the callback returns. Returning ErrSkipLinks does not follow the links
+	// of the page, and returning any other error stops the crawl.
+	OnPage func(page *CrawlPage) error
+}
+
+// CrawlPage is a page visited by a crawl.
+type CrawlPage struct {
+	*Puppet
+	URL      string
+	Referrer string
+	Depth    int
+	// Err is the error of the navigation to the page, if any.
+	Err error
+	// Data is the data extracted from the page by the page callback, saved with the result of the page.
+	Data []byte
+}
+
+type crawlResult struct {
+	item  CrawlItem
+	links []string
+	data  []byte
+	// navErr is the error of the navigation to the page, and err the error stopping the crawl
+	navErr error
+	err    error
+	// skipped reports whether the page was not visited, e.g. disallowed by the robots.txt
+	skipped bool
+}
+
+// Crawl visits the start url and follows the links of the pages of the same site breadth first,
+// visiting the urls as linked, once per normalized url, politely unless disabled. With a persistent store,
+// a crawl interrupted resumes where it left off when started again.
+func (c *Puppet) Crawl(startURL string, opts CrawlOptions) (err error) {
+	start, err := normalizeURL(startURL)
+	if err != nil {
+		return err
+	}
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if opts.Politeness == nil && !opts.Impolite {
+		opts.Politeness = NewPoliteness()
+	}
+	store := opts.Store
+	if store == nil {
+		store = NewMemoryCrawlStore()
+	}
+	site := siteOf(start)
+	inScope := func(u string) bool {
+		host := hostOf(u)
+		if matchNavigation(opts.Deny, u, host) {
+			return false
+		}
+		return matchNavigation(opts.Allow, u, host) ||
+			(len(opts.Allow) == 0 && siteOf(u) == site)
+	}
+	enqueue := func(link, referrer string, depth int) error {
+		key, err := normalizeURL(link)
+		if err != nil || !inScope(key) {
+			return nil
+		}
+		return store.Enqueue(CrawlItem{URL: link, Key: key, Referrer: referrer, Depth: depth})
+	}
+
+	err = store.Enqueue(CrawlItem{URL: startURL, Key: start})
+	if err != nil {
+		return err
+	}
+	for _, link := range opts.Seeds {
+		err = enqueue(link, "", 0)
+		if err != nil {
+			return err
+		}
+	}
+	for _, sitemap := range opts.Sitemaps {
+		var cli *http.Client
+		if opts.Politeness != nil {
+			cli = opts.Politeness.Client
+		}
+		urls, err := ReadSitemap(c.ctx, cli, sitemap)
+		if err != nil {
+			return err
+		}
+		for _, u := range urls {
+			err = enqueue(u.Loc, sitemap, 0)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	tabs := make([]*Puppet, 0, concurrency)
+	defer func() {
+		for _, tab := range tabs {
+			tab.Close()
+		}
+	}()
+	for i := 0; i != concurrency; i++ {
+		id, err := c.NewTarget("about:blank")
+		if err != nil {
+			return err
+		}
+		tabs = append(tabs, c.Tab(id))
+	}
+
+	work := make(chan CrawlItem)
+	results := make(chan crawlResult)
+	var wg sync.WaitGroup
+	for _, tab := range tabs {
+		wg.Add(1)
+		go func(tab *Puppet) {
+			defer wg.Done()
+			for item := range work {
+				results <- tab.crawlPage(item, opts)
+			}
+		}(tab)
+	}
+	defer func() {
+		close(work)
+		wg.Wait()
+	}()
+
+	save := func(r crawlResult) error {
+		result := CrawlResult{
+			URL:      r.item.URL,
+			Key:      r.item.Key,
+			Referrer: r.item.Referrer,
+			Depth:    r.item.Depth,
+			Skipped:  r.skipped,
+			Data:     r.data,
+			Time:     time.Now(),
+		}
+		if r.navErr != nil {
+			result.Error = r.navErr.Error()
+		}
+		return store.SaveResult(result)
+	}
+
+	var next *CrawlItem
+	active, visited := 0, 0
+	// drain waits for the pages being visited, saving their results so they are not visited again on resume
+	drain := func() {
+		for ; active != 0; active-- {
+			r := <-results
+			if r.err == nil {
+				save(r)
+			}
+		}
+	}
+	for {
+		if next == nil && (opts.MaxPages == 0 || visited < opts.MaxPages) {
+			item, ok, err := store.Dequeue()
+			if err != nil {
+				drain()
+				return err
+			}
+			if ok {
+				next = &item
+			}
+		}
+		var send chan CrawlItem
+		var item CrawlItem
+		if next != nil {
+			send, item = work, *next
+		}
+		if send == nil && active == 0 {
+			return nil
+		}
+		select {
+		case <-c.ctx.Done():
+			drain()
+			return c.ctx.Err()
+		case send <- item:
+			next = nil
+			active++
+			visited++
+		case r := <-results:
+			active--
+			if r.skipped {
+				visited--
+			}
+			if r.err != nil {
+				drain()
+				return r.err
+			}
+			err = save(r)
+			if err != nil {
+				drain()
+				return err
+			}
+			if opts.MaxDepth != 0 && r.item.Depth >= opts.MaxDepth {
+				continue
+			}
+			for _, link := range r.links {
+				err = enqueue(link, r.item.URL, r.item.Depth+1)
+				if err != nil {
+					drain()
+					return err
+				}
+			}
+		}
+	}
+}
+
+// crawlPage visits the page of the item and returns its result with the links of the page.
+func (c *Puppet) crawlPage(item CrawlItem, opts CrawlOptions) (r crawlResult) {
+	r.item = item
+	if p := opts.Politeness; p != nil {
+		ok, err := p.Allowed(c.ctx, item.URL)
+		if err != nil {
+			r.err = err
+			return r
+		}
+		if !ok {
+			r.skipped = true
+			return r
+		}
+		err = p.Wait(c.ctx, item.URL)
+		if err != nil {
+			r.err = err
+			return r
+		}
+	}
+	page := &CrawlPage{
+		Puppet:   c,
+		URL:      item.URL,
+		Referrer: item.Referrer,
+		Depth:    item.Depth,
+	}
+	page.Err = c.Navigate(item.URL)
+	r.navErr = page.Err
+	if opts.OnPage != nil {
+		err := opts.OnPage(page)
+		r.data = page.Data
+		if errors.Is(err, ErrSkipLinks) {
+			return r
+		}
+		if err != nil {
+			r.err = err
+			return r
+		}
+	}
+	if page.Err != nil {
+		return r
+	}
+
+	sel := opts.Links
+	if sel == "" {
+		sel = "a[href]"
+	}
+	data, err := json.Marshal(sel)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	c.Evaluate(`Array.prototype.map.call(document.querySelectorAll(`+string(data)+`), function (e) {
+	return e.href && e.href.baseVal !== undefined ? e.href.baseVal : e.href;
+}).filter(function (href) { return typeof href === "string" && href; })`, &r.links)
+	return r
+}
+
+// normalizeURL normalizes the http or https url for deduplication, dropping the fragment
+// and the default port, lower casing the scheme and the host, and sorting the query.
+func normalizeURL(rawurl string) (string, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", errors.New("unsupported scheme " + u.Scheme)
+	}
+	u.Fragment = ""
+	u.User = nil
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	switch {
+	case port != "":
+		u.Host = net.JoinHostPort(host, port)
+	case strings.Contains(host, ":"):
+		u.Host = "[" + host + "]"
+	default:
+		u.Host = host
+	}
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	q := u.Query()
+	for _, vs := range q {
+		sort.Strings(vs)
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
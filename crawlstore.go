@@ -0,0 +1,105 @@
+package puppet
+
+import (
+	"sync"
+	"time"
+)
+
+// CrawlItem is an url to visit by a crawl.
+type CrawlItem struct {
+	URL string `json:"url"`
+	// Key is the normalized url the items are deduplicated by.
+	Key      string `json:"key"`
+	Referrer string `json:"referrer,omitempty"`
+	Depth    int    `json:"depth"`
+}
+
+// CrawlResult is the result of the visit of an url by a crawl.
+type CrawlResult struct {
+	URL      string `json:"url"`
+	Key      string `json:"key"`
+	Referrer string `json:"referrer,omitempty"`
+	Depth    int    `json:"depth"`
+	// Skipped reports whether the url was not visited, e.g. disallowed by the robots.txt.
+	Skipped bool `json:"skipped,omitempty"`
+	// Error is the error of the navigation to the url, if any.
+	Error string `json:"error,omitempty"`
+	// Data is the data extracted from the page by the page callback.
+	Data []byte    `json:"data,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// CrawlStore is the frontier and the results of a crawl, a persistent store lets an interrupted crawl resume.
+type CrawlStore interface {
+	// Enqueue adds the item to the frontier unless its key has been seen.
+	Enqueue(item CrawlItem) error
+	// Dequeue removes the next item from the frontier, ok is false if the frontier is empty.
+	// The item is pending until its result is saved, and a persistent store enqueues
+	// the pending items again when it is reopened.
+	Dequeue() (item CrawlItem, ok bool, err error)
+	// Seen reports whether an item with the key has been enqueued.
+	Seen(key string) (bool, error)
+	// SaveResult saves the result of the visit of an item.
+	SaveResult(result CrawlResult) error
+}
+
+// MemoryCrawlStore is a CrawlStore in memory.
+type MemoryCrawlStore struct {
+	mu      sync.Mutex
+	queue   []CrawlItem
+	seen    map[string]bool
+	results []CrawlResult
+}
+
+// NewMemoryCrawlStore returns a new CrawlStore in memory.
+func NewMemoryCrawlStore() *MemoryCrawlStore {
+	return &MemoryCrawlStore{
+		seen: map[string]bool{},
+	}
+}
+
+// Enqueue adds the item to the frontier unless its key has been seen.
+func (s *MemoryCrawlStore) Enqueue(item CrawlItem) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.seen[item.Key] {
+		return nil
+	}
+	s.seen[item.Key] = true
+	s.queue = append(s.queue, item)
+	return nil
+}
+
+// Dequeue removes the next item from the frontier.
+func (s *MemoryCrawlStore) Dequeue() (item CrawlItem, ok bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return CrawlItem{}, false, nil
+	}
+	item = s.queue[0]
+	s.queue = s.queue[1:]
+	return item, true, nil
+}
+
+// Seen reports whether an item with the key has been enqueued.
+func (s *MemoryCrawlStore) Seen(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seen[key], nil
+}
+
+// SaveResult saves the result of the visit of an item.
+func (s *MemoryCrawlStore) SaveResult(result CrawlResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results = append(s.results, result)
+	return nil
+}
+
+// Results returns the results saved.
+func (s *MemoryCrawlStore) Results() []CrawlResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]CrawlResult(nil), s.results...)
+}
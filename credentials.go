@@ -0,0 +1,96 @@
+package puppet
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CredentialVault maps origins to the headers sent with their requests only, e.g. authorization tokens,
+// so the credentials of one site are never sent to another as with SetHeaders.
+type CredentialVault struct {
+	mu       sync.RWMutex
+	origins  map[string]http.Header
+	patterns []*originPattern
+}
+
+type originPattern struct {
+	re     *regexp.Regexp
+	header http.Header
+}
+
+// NewCredentialVault creates a new empty CredentialVault.
+func NewCredentialVault() *CredentialVault {
+	return &CredentialVault{
+		origins: map[string]http.Header{},
+	}
+}
+
+// SetHeader sets the headers sent with the requests to the origin, e.g. "https://api.example.com",
+// where * matches any sequence of characters, e.g. "https://*.example.com".
+func (v *CredentialVault) SetHeader(origin string, header http.Header) {
+	origin = strings.TrimSuffix(strings.ToLower(origin), "/")
+	// the header is cloned, so changes of the caller don't race with the requests
+	header = header.Clone()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if strings.Contains(origin, "*") {
+		v.patterns = append(v.patterns, &originPattern{
+			re:     globRegexp(origin),
+			header: header,
+		})
+		return
+	}
+	v.origins[origin] = header
+}
+
+// SetBasicAuth sends the basic authorization with the requests to the origin.
+func (v *CredentialVault) SetBasicAuth(origin string, username, password string) {
+	v.SetHeader(origin, http.Header{
+		"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))},
+	})
+}
+
+// SetBearer sends the bearer token with the requests to the origin.
+func (v *CredentialVault) SetBearer(origin string, token string) {
+	v.SetHeader(origin, http.Header{
+		"Authorization": {"Bearer " + token},
+	})
+}
+
+// Header returns the headers sent with the request to the url, nil if none.
+func (v *CredentialVault) Header(rawurl string) http.Header {
+	u, err := url.Parse(rawurl)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if header, ok := v.origins[origin]; ok {
+		return header.Clone()
+	}
+	for _, p := range v.patterns {
+		if p.re.MatchString(origin) {
+			return p.header.Clone()
+		}
+	}
+	return nil
+}
+
+// UseCredentialVault adds the headers of the vault to the requests of the current target to their origins.
+func (c *Puppet) UseCredentialVault(v *CredentialVault) (err error) {
+	return c.interceptOverride(nil, func(r *Request) {
+		header := v.Header(r.URL)
+		if len(header) == 0 {
+			return
+		}
+		for k, vs := range header {
+			r.Header[http.CanonicalHeaderKey(k)] = append([]string(nil), vs...)
+		}
+		r.Continue()
+	})
+}
@@ -0,0 +1,137 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// CSPViolation is a Content-Security-Policy violation reported by a page.
+type CSPViolation struct {
+	DocumentURL    string `json:"documentURL"`
+	BlockedURL     string `json:"blockedURL"`
+	Directive      string `json:"directive"`
+	OriginalPolicy string `json:"originalPolicy"`
+	Disposition    string `json:"disposition"`
+	SourceFile     string `json:"sourceFile"`
+	LineNumber     int    `json:"lineNumber"`
+	ColumnNumber   int    `json:"columnNumber"`
+}
+
+const cspBinding = "__puppetCSPViolation"
+
+const cspListener = `document.addEventListener("securitypolicyviolation", function (e) {
+	window.` + cspBinding + `(JSON.stringify({
+		documentURL: e.documentURI,
+		blockedURL: e.blockedURI,
+		directive: e.effectiveDirective || e.violatedDirective,
+		originalPolicy: e.originalPolicy,
+		disposition: e.disposition,
+		sourceFile: e.sourceFile,
+		lineNumber: e.lineNumber,
+		columnNumber: e.columnNumber
+	}));
+}, true);`
+
+// CollectCSPViolations starts collecting the Content-Security-Policy violations of the current target,
+// the violations collected are reset on every navigation of the main frame.
+func (c *Puppet) CollectCSPViolations() (err error) {
+	// the binding calls are reported to the session which added the binding
+	err = c.runSession(runtime.AddBinding(cspBinding))
+	if err != nil {
+		return err
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(cspListener).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+
+	requests := map[network.RequestID]string{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				c.mu.Lock()
+				c.cspViolations = nil
+				c.mu.Unlock()
+			}
+		case *runtime.EventBindingCalled:
+			if ev.Name != cspBinding {
+				return
+			}
+			v := &CSPViolation{}
+			if json.Unmarshal([]byte(ev.Payload), v) != nil {
+				return
+			}
+			c.addCSPViolation(v)
+		case *requestWillBeSent:
+			requests[ev.RequestID] = ev.Request.URL
+		case *network.EventLoadingFailed:
+			url := requests[ev.RequestID]
+			delete(requests, ev.RequestID)
+			if ev.BlockedReason != network.BlockedReasonCsp {
+				return
+			}
+			c.addCSPViolation(&CSPViolation{
+				BlockedURL:  url,
+				Disposition: "enforce",
+			})
+		case *network.EventLoadingFinished:
+			delete(requests, ev.RequestID)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventRuntimeBindingCalled,
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkLoadingFailed,
+		cdproto.EventNetworkLoadingFinished,
+	)
+}
+
+// CSPViolations returns the Content-Security-Policy violations collected since the last navigation.
+func (c *Puppet) CSPViolations() []*CSPViolation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*CSPViolation(nil), c.cspViolations...)
+}
+
+// addCSPViolation adds the violation, merging the violations of the same blocked url
+// reported by both the page and the network.
+func (c *Puppet) addCSPViolation(v *CSPViolation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, o := range c.cspViolations {
+		if o.BlockedURL != v.BlockedURL || (o.Directive != "" && v.Directive != "") {
+			continue
+		}
+		if o.Directive == "" {
+			c.cspViolations[i] = v
+		}
+		return
+	}
+	c.cspViolations = append(c.cspViolations, v)
+}
+
+// SetBypassCSP enables or disables bypassing the Content-Security-Policy of the pages of the current target,
+// so scripts injected by Evaluate are not blocked by strict policies. It applies to the pages loaded afterwards.
+func (c *Puppet) SetBypassCSP(enabled bool) (err error) {
+	err = c.run(c.ctx,
+		page.SetBypassCSP(enabled))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.BypassCSP = enabled
+	})
+	return nil
+}
@@ -0,0 +1,34 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestCollectCSPViolations(t *testing.T) {
+	p, b := newFakePuppet(t)
+	err := p.CollectCSPViolations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := b.enabled("Network"); n != 1 {
+		t.Fatalf("Network enabled by %d connections, want 1", n)
+	}
+
+	b.emit("Network.requestWillBeSent", `{"requestId":"1","loaderId":"L","documentURL":"https://example.com/","request":{"url":"https://evil.example/x.js","method":"GET","headers":{}}}`)
+	b.emit("Network.loadingFailed", `{"requestId":"1","type":"Script","errorText":"","blockedReason":"csp"}`)
+	eventually(t, func() bool {
+		return len(p.CSPViolations()) == 1
+	})
+	v := p.CSPViolations()[0]
+	if v.BlockedURL != "https://evil.example/x.js" || v.Disposition != "enforce" {
+		t.Errorf("got violation %+v", v)
+	}
+
+	err = p.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		return b.enabled("Network") == 0
+	})
+}
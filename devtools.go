@@ -0,0 +1,213 @@
+package puppet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// DevToolsHandler returns a handler exposing the DevTools endpoint of the browser,
+// so other tools, e.g. the DevTools UI or Lighthouse, can attach to the targets while the Puppet stays attached.
+// Commands that would end the session of the Puppet, closing the browser or the targets it is attached to,
+// are rejected. The handler has no authentication, whoever reaches it controls the browser.
+func (c *Puppet) DevToolsHandler() http.Handler {
+	p := &devToolsProxy{
+		c: c,
+	}
+	p.upgrader.CheckOrigin = checkDevToolsOrigin
+	return p
+}
+
+// ServeDevTools serves the DevTools endpoint of the browser on the address, on the loopback interface
+// if the address has no host, e.g. ":9229". Serving it on other interfaces exposes the browser
+// without authentication.
+func (c *Puppet) ServeDevTools(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" {
+		addr = net.JoinHostPort("127.0.0.1", port)
+	}
+	return http.ListenAndServe(addr, c.DevToolsHandler())
+}
+
+// checkDevToolsOrigin accepts the connections of tools, without an origin, of the DevTools UI,
+// and of the pages served by the handler itself.
+func checkDevToolsOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || strings.HasPrefix(origin, "devtools://") {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+type devToolsProxy struct {
+	c        *Puppet
+	upgrader websocket.Upgrader
+}
+
+// reserved are the methods that would end the session of the Puppet.
+var reserved = map[string]bool{
+	"Browser.close":                true,
+	"Browser.crash":                true,
+	"Target.disposeBrowserContext": true,
+}
+
+// reserves reports whether the command would end the session of the Puppet,
+// closing the browser or a target the Puppet is attached to.
+func (p *devToolsProxy) reserves(method string, params json.RawMessage) bool {
+	if reserved[method] {
+		return true
+	}
+	if method != "Target.closeTarget" {
+		return false
+	}
+	var v struct {
+		TargetID string `json:"targetId"`
+	}
+	if json.Unmarshal(params, &v) != nil {
+		return false
+	}
+	if v.TargetID == p.c.target {
+		return true
+	}
+	for _, id := range p.c.cdp.ListTargets() {
+		if id == v.TargetID {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *devToolsProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	base, err := url.Parse(strings.TrimSuffix(p.c.endpoint, "/json"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/devtools/") {
+		p.serveWebSocket(w, r, base)
+		return
+	}
+	p.serveHTTP(w, r, base)
+}
+
+// serveHTTP proxies the discovery endpoints, rewriting the urls of the targets to the proxy.
+func (p *devToolsProxy) serveHTTP(w http.ResponseWriter, r *http.Request, base *url.URL) {
+	u := *base
+	u.Path = r.URL.Path
+	u.RawQuery = r.URL.RawQuery
+	req, err := http.NewRequest(r.Method, u.String(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(r.Context()))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if strings.HasPrefix(r.URL.Path, "/json") {
+		body = []byte(strings.Replace(string(body), base.Host, r.Host, -1))
+	}
+	for k, vs := range resp.Header {
+		if k == "Content-Length" {
+			continue
+		}
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
+
+// serveWebSocket proxies the DevTools protocol connection, rejecting the reserved methods.
+func (p *devToolsProxy) serveWebSocket(w http.ResponseWriter, r *http.Request, base *url.URL) {
+	u := *base
+	u.Scheme = "ws"
+	if base.Scheme == "https" {
+		u.Scheme = "wss"
+	}
+	u.Path = r.URL.Path
+	upstream, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+
+	conn, err := p.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// gorilla connections support only one concurrent writer
+	var mu sync.Mutex
+	write := func(typ int, msg []byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return conn.WriteMessage(typ, msg)
+	}
+
+	errc := make(chan error, 2)
+	go func() {
+		for {
+			typ, msg, err := upstream.ReadMessage()
+			if err != nil {
+				errc <- err
+				return
+			}
+			err = write(typ, msg)
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	go func() {
+		for {
+			typ, msg, err := conn.ReadMessage()
+			if err != nil {
+				errc <- err
+				return
+			}
+			var cmd struct {
+				ID     int64           `json:"id"`
+				Method string          `json:"method"`
+				Params json.RawMessage `json:"params"`
+			}
+			if json.Unmarshal(msg, &cmd) == nil && p.reserves(cmd.Method, cmd.Params) {
+				resp, _ := json.Marshal(map[string]interface{}{
+					"id": cmd.ID,
+					"error": map[string]interface{}{
+						"code":    -32000,
+						"message": cmd.Method + " is reserved by puppet",
+					},
+				})
+				err = write(websocket.TextMessage, resp)
+			} else {
+				err = upstream.WriteMessage(typ, msg)
+			}
+			if err != nil {
+				errc <- err
+				return
+			}
+		}
+	}()
+	<-errc
+}
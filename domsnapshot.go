@@ -0,0 +1,48 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/domsnapshot"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultSnapshotStyles are the computed styles captured by DOMSnapshot when none are specified.
+var defaultSnapshotStyles = []string{
+	"display",
+	"visibility",
+	"position",
+	"font-size",
+	"font-weight",
+	"color",
+	"background-color",
+	"z-index",
+	"opacity",
+}
+
+// DOMSnapshot returns the flattened DOM of the current page and its frames as JSON, with the layout of the nodes
+// and the computed styles, defaultSnapshotStyles if none specified. The JSON has the "documents" and the "strings"
+// the documents index into, as of the DOMSnapshot.captureSnapshot command of the DevTools protocol.
+func (c *Puppet) DOMSnapshot(computedStyles ...string) (res []byte, err error) {
+	if len(computedStyles) == 0 {
+		computedStyles = defaultSnapshotStyles
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		documents, strings, err := domsnapshot.CaptureSnapshot(computedStyles).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		res, err = json.Marshal(struct {
+			Documents []*domsnapshot.DocumentSnapshot `json:"documents"`
+			Strings   []string                        `json:"strings"`
+		}{documents, strings})
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
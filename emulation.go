@@ -0,0 +1,241 @@
+package puppet
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
+
+// SetUserAgent overrides the user agent of the current target,
+// the user agent client hints are derived from it so the sec-ch-ua headers stay consistent.
+func (c *Puppet) SetUserAgent(ua string) (err error) {
+	lang := c.Overrides().AcceptLanguage
+	err = c.setUserAgentOverride(ua, lang)
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.UserAgent = ua
+	})
+	return nil
+}
+
+// SetAcceptLanguage overrides the Accept-Language header and navigator.languages of the current target.
+func (c *Puppet) SetAcceptLanguage(lang string) (err error) {
+	ua := c.Overrides().UserAgent
+	if ua == "" {
+		err = c.run(c.ctx,
+			chromedp.Evaluate(`navigator.userAgent`, &ua))
+		if err != nil {
+			return err
+		}
+	}
+	err = c.setUserAgentOverride(ua, lang)
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.AcceptLanguage = lang
+	})
+	return nil
+}
+
+func (c *Puppet) setUserAgentOverride(ua, lang string) (err error) {
+	return c.run(c.ctx,
+		userAgentOverride(ua, lang))
+}
+
+// userAgentOverrideParams are the parameters of Emulation.setUserAgentOverride, with the client hints cdproto lacks.
+type userAgentOverrideParams struct {
+	UserAgent         string          `json:"userAgent"`
+	AcceptLanguage    string          `json:"acceptLanguage,omitempty"`
+	Platform          string          `json:"platform,omitempty"`
+	UserAgentMetadata *userAgentHints `json:"userAgentMetadata,omitempty"`
+}
+
+// userAgentHints are the user agent client hints reported by the sec-ch-ua headers and navigator.userAgentData.
+type userAgentHints struct {
+	Brands          []*brandVersion `json:"brands,omitempty"`
+	FullVersion     string          `json:"fullVersion,omitempty"`
+	Platform        string          `json:"platform"`
+	PlatformVersion string          `json:"platformVersion"`
+	Architecture    string          `json:"architecture"`
+	Model           string          `json:"model"`
+	Mobile          bool            `json:"mobile"`
+}
+
+// brandVersion is a brand of the user agent client hints.
+type brandVersion struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version"`
+}
+
+// userAgentOverride returns an action that overrides the user agent and the accept language.
+func userAgentOverride(ua, lang string) chromedp.Action {
+	params := &userAgentOverrideParams{
+		UserAgent:      ua,
+		AcceptLanguage: lang,
+	}
+	if ua != "" {
+		meta := userAgentMetadata(ua)
+		params.Platform = navigatorPlatform(ua, meta)
+		params.UserAgentMetadata = meta
+	}
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		return execute(ctx, h, "Emulation.setUserAgentOverride", params, nil)
+	})
+}
+
+// EmulateMedia emulates the CSS media type of the current target, e.g. "print" or "screen",
+// an empty media type disables the emulation.
+func (c *Puppet) EmulateMedia(media string) (err error) {
+	features := c.Overrides().MediaFeatures
+	err = c.run(c.ctx,
+		emulatedMedia(media, features))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.Media = media
+	})
+	return nil
+}
+
+// EmulateMediaFeatures emulates the CSS media features of the current target,
+// e.g. {"prefers-color-scheme": "dark", "prefers-reduced-motion": "reduce"}.
+func (c *Puppet) EmulateMediaFeatures(features map[string]string) (err error) {
+	// the features are copied so later changes by the caller do not change the overrides
+	copied := make(map[string]string, len(features))
+	for name, value := range features {
+		copied[name] = value
+	}
+	features = copied
+	media := c.Overrides().Media
+	err = c.run(c.ctx,
+		emulatedMedia(media, features))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.MediaFeatures = features
+	})
+	return nil
+}
+
+// emulatedMediaParams are the parameters of Emulation.setEmulatedMedia, with the media features cdproto lacks.
+type emulatedMediaParams struct {
+	Media    string          `json:"media"`
+	Features []*mediaFeature `json:"features,omitempty"`
+}
+
+// mediaFeature is an emulated CSS media feature.
+type mediaFeature struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// emulatedMedia returns an action that emulates the media type and the media features.
+func emulatedMedia(media string, features map[string]string) chromedp.Action {
+	params := &emulatedMediaParams{
+		Media: media,
+	}
+	for name, value := range features {
+		params.Features = append(params.Features, &mediaFeature{
+			Name:  name,
+			Value: value,
+		})
+	}
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		return execute(ctx, h, "Emulation.setEmulatedMedia", params, nil)
+	})
+}
+
+// SetJavaScriptEnabled enables or disables the execution of the scripts of the pages of the current target,
+// e.g. to render the server side markup only.
+func (c *Puppet) SetJavaScriptEnabled(enabled bool) (err error) {
+	err = c.run(c.ctx,
+		emulation.SetScriptExecutionDisabled(!enabled))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.JavaScriptDisabled = !enabled
+	})
+	return nil
+}
+
+var chromeVersion = regexp.MustCompile(`(?:Chrome|Chromium|CriOS)/((\d+)[\d.]*)`)
+
+// userAgentMetadata derives the user agent client hints from the user agent string.
+func userAgentMetadata(ua string) *userAgentHints {
+	meta := &userAgentHints{
+		Mobile: strings.Contains(ua, "Mobile"),
+	}
+
+	switch {
+	case strings.Contains(ua, "Android"):
+		meta.Platform = "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"):
+		meta.Platform = "iOS"
+	case strings.Contains(ua, "Windows"):
+		meta.Platform = "Windows"
+	case strings.Contains(ua, "Macintosh"):
+		meta.Platform = "macOS"
+	case strings.Contains(ua, "CrOS"):
+		meta.Platform = "Chrome OS"
+	case strings.Contains(ua, "Linux"):
+		meta.Platform = "Linux"
+	}
+
+	if strings.Contains(ua, "x86_64") || strings.Contains(ua, "Win64") || strings.Contains(ua, "Intel") {
+		meta.Architecture = "x86"
+	} else if strings.Contains(ua, "arm") || strings.Contains(ua, "aarch64") {
+		meta.Architecture = "arm"
+	}
+
+	if m := chromeVersion.FindStringSubmatch(ua); m != nil {
+		meta.FullVersion = m[1]
+		meta.Brands = []*brandVersion{
+			{Brand: "Not A Brand", Version: "99"},
+			{Brand: "Chromium", Version: m[2]},
+			{Brand: "Google Chrome", Version: m[2]},
+		}
+	}
+	return meta
+}
+
+// navigatorPlatform returns the navigator.platform of the user agent with the metadata, e.g. "Win32" or "MacIntel",
+// which differs from the platform of the client hints.
+func navigatorPlatform(ua string, meta *userAgentHints) string {
+	switch meta.Platform {
+	case "Windows":
+		return "Win32"
+	case "macOS":
+		return "MacIntel"
+	case "iOS":
+		if strings.Contains(ua, "iPad") {
+			return "iPad"
+		}
+		return "iPhone"
+	case "Android":
+		if strings.Contains(ua, "aarch64") {
+			return "Linux aarch64"
+		}
+		return "Linux armv8l"
+	case "Linux", "Chrome OS":
+		switch {
+		case strings.Contains(ua, "aarch64"):
+			return "Linux aarch64"
+		case strings.Contains(ua, "armv7l"):
+			return "Linux armv7l"
+		case strings.Contains(ua, "i686"):
+			return "Linux i686"
+		}
+		return "Linux x86_64"
+	}
+	return ""
+}
@@ -0,0 +1,166 @@
+package puppet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// The errors the errors returned by the actions can be matched against with errors.Is.
+var (
+	// ErrNodeNotFound is returned when no node matches a selector.
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrTimeout is returned when an action does not complete in time.
+	ErrTimeout = errors.New("timeout")
+	// ErrNavigationFailed is returned when a navigation fails, the error is a *NavigationError.
+	ErrNavigationFailed = errors.New("navigation failed")
+	// ErrTargetCrashed is returned when the target crashed or was closed under an action.
+	ErrTargetCrashed = errors.New("target crashed")
+	// ErrDetached is returned when a node was removed from the document under an action.
+	ErrDetached = errors.New("node detached")
+	// ErrTargetNotFound is returned when no target has the id an action is run against.
+	ErrTargetNotFound = errors.New("target not found")
+)
+
+// NavigationError is the error of a failed navigation, by a network error or an HTTP error status.
+type NavigationError struct {
+	URL string
+	// StatusCode is the status code of the response, zero if no response was received.
+	StatusCode int
+	// Reason is the network error reported by the browser, e.g. "net::ERR_NAME_NOT_RESOLVED",
+	// or else the text of the HTTP error status, e.g. "Not Found".
+	Reason string
+}
+
+func (e *NavigationError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("navigate %s: %s (status %d)", e.URL, e.Reason, e.StatusCode)
+	}
+	return fmt.Sprintf("navigate %s: %s", e.URL, e.Reason)
+}
+
+// Is reports whether the target is ErrNavigationFailed.
+func (e *NavigationError) Is(target error) bool {
+	return target == ErrNavigationFailed
+}
+
+// actionError is an error of an action classified as one of the sentinel errors.
+type actionError struct {
+	kind error
+	err  error
+}
+
+func (e *actionError) Error() string {
+	return e.err.Error()
+}
+
+func (e *actionError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *actionError) Unwrap() error {
+	return e.err
+}
+
+// wrapError classifies the error of an action by its source, keeping the original error available
+// to errors.Is and errors.As. Errors already classified, e.g. wrapping one of the sentinel errors, are kept.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range []error{ErrNodeNotFound, ErrTimeout, ErrNavigationFailed, ErrTargetCrashed, ErrDetached, ErrTargetNotFound} {
+		if errors.Is(err, kind) {
+			return err
+		}
+	}
+	var kind error
+	var protocol *cdproto.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = ErrTimeout
+	case errors.Is(err, chromedp.ErrNoResults):
+		kind = ErrNodeNotFound
+	case errors.Is(err, chromedp.ErrChannelClosed) || errors.Is(err, chromedp.ErrInvalidHandler):
+		kind = ErrTargetCrashed
+	case errors.As(err, &protocol):
+		kind = protocolErrorKind(protocol)
+	}
+	if kind == nil {
+		return err
+	}
+	return &actionError{kind: kind, err: err}
+}
+
+// protocolErrorKind returns the sentinel error of the error returned by the browser, nil if unclassified.
+func protocolErrorKind(e *cdproto.Error) error {
+	msg := strings.ToLower(e.Message)
+	switch {
+	case strings.Contains(msg, "target crashed") || strings.Contains(msg, "target closed") || strings.Contains(msg, "inspected target navigated or closed"):
+		return ErrTargetCrashed
+	case strings.Contains(msg, "could not find node with given id") || strings.Contains(msg, "no node with given id found") ||
+		strings.Contains(msg, "node with given id does not belong to the document") || strings.Contains(msg, "node is detached"):
+		return ErrDetached
+	}
+	return nil
+}
+
+// navigate is an action that navigates the current frame to the url,
+// and fails with a *NavigationError if the browser could not load it.
+func (c *Puppet) navigate(url string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		s, err := c.sessionOf(h)
+		if err != nil {
+			return err
+		}
+		statuses := map[cdp.LoaderID]int{}
+		l, err := s.add(ctx, func(ev interface{}) {
+			if resp, ok := ev.(*network.EventResponseReceived); ok && resp.Type == network.ResourceTypeDocument {
+				statuses[resp.LoaderID] = int(resp.Response.Status)
+			}
+		}, cdproto.EventNetworkResponseReceived)
+		if err != nil {
+			return err
+		}
+
+		_, loaderID, errorText, err := page.Navigate(url).
+			Do(ctx, h)
+		if err == nil {
+			// the response is received by the session before the round trip completes
+			err = s.sync(ctx)
+		}
+		s.remove(l)
+		l.wait()
+		if err != nil {
+			return err
+		}
+		status := statuses[loaderID]
+		if errorText != "" {
+			return &NavigationError{
+				URL:        url,
+				StatusCode: status,
+				Reason:     errorText,
+			}
+		}
+		if status >= 400 {
+			// the error page is loaded as any other page
+			err = waitComplete.Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			return &NavigationError{
+				URL:        url,
+				StatusCode: status,
+				Reason:     http.StatusText(status),
+			}
+		}
+		return nil
+	})
+}
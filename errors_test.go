@@ -0,0 +1,91 @@
+package puppet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/chromedp"
+)
+
+func TestWrapError(t *testing.T) {
+	navigation := &NavigationError{URL: "https://example.com/", StatusCode: 404, Reason: "Not Found"}
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{context.DeadlineExceeded, ErrTimeout},
+		{fmt.Errorf("wait: %w", context.DeadlineExceeded), ErrTimeout},
+		{chromedp.ErrNoResults, ErrNodeNotFound},
+		{chromedp.ErrChannelClosed, ErrTargetCrashed},
+		{chromedp.ErrInvalidHandler, ErrTargetCrashed},
+		{&cdproto.Error{Code: -32000, Message: "Could not find node with given id"}, ErrDetached},
+		{&cdproto.Error{Code: -32000, Message: "Node is detached from document"}, ErrDetached},
+		{&cdproto.Error{Code: -32000, Message: "Target closed"}, ErrTargetCrashed},
+		{&cdproto.Error{Code: -32000, Message: "Cannot find context with specified id"}, nil},
+		{navigation, ErrNavigationFailed},
+		{errors.New("other"), nil},
+	}
+	kinds := []error{ErrNodeNotFound, ErrTimeout, ErrNavigationFailed, ErrTargetCrashed, ErrDetached, ErrTargetNotFound}
+	for _, tt := range tests {
+		got := wrapError(tt.err)
+		if !errors.Is(got, tt.err) {
+			t.Errorf("wrapError(%v) = %v, does not wrap the error", tt.err, got)
+		}
+		if got.Error() != tt.err.Error() {
+			t.Errorf("wrapError(%v) = %q, want the message %q", tt.err, got.Error(), tt.err.Error())
+		}
+		for _, kind := range kinds {
+			if is := errors.Is(got, kind); is != (kind == tt.want) {
+				t.Errorf("errors.Is(wrapError(%v), %v) = %v, want %v", tt.err, kind, is, !is)
+			}
+		}
+	}
+
+	if wrapError(nil) != nil {
+		t.Errorf("wrapError(nil) != nil")
+	}
+	if err := wrapError(navigation); err != navigation {
+		t.Errorf("wrapError(%v) = %#v, want the error kept", navigation, err)
+	}
+	wrapped := wrapError(context.DeadlineExceeded)
+	if err := wrapError(wrapped); err != wrapped {
+		t.Errorf("wrapError(%v) = %#v, want the classified error kept", wrapped, err)
+	}
+}
+
+func TestNavigateStatus(t *testing.T) {
+	p, b := newFakePuppet(t)
+	b.mu.Lock()
+	b.results["Page.navigate"] = `{"frameId":"F","loaderId":"L2"}`
+	b.results["Runtime.evaluate"] = `{"result":{"type":"string","value":"complete"}}`
+	b.hooks["Page.navigate"] = func() {
+		b.emit("Network.responseReceived", `{"requestId":"R","loaderId":"L2","timestamp":1,"type":"Document",`+
+			`"response":{"url":"https://example.com/missing","status":404,"statusText":"Not Found","headers":{},"mimeType":"text/html",`+
+			`"connectionReused":false,"connectionId":0,"encodedDataLength":0,"securityState":"secure"},"frameId":"F"}`)
+	}
+	b.mu.Unlock()
+
+	err := p.Navigate("https://example.com/missing")
+	var navigation *NavigationError
+	if !errors.As(err, &navigation) {
+		t.Fatalf("got error %v, want a navigation error", err)
+	}
+	if navigation.StatusCode != 404 {
+		t.Fatalf("got status %d, want 404", navigation.StatusCode)
+	}
+	if !errors.Is(err, ErrNavigationFailed) {
+		t.Fatalf("errors.Is(%v, ErrNavigationFailed) = false", err)
+	}
+}
+
+func TestTargetNotFound(t *testing.T) {
+	p, _ := newFakePuppet(t)
+
+	_, err := p.Tab("missing").Title()
+	if !errors.Is(err, ErrTargetNotFound) {
+		t.Fatalf("got error %v, want ErrTargetNotFound", err)
+	}
+}
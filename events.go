@@ -0,0 +1,550 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/client"
+	"github.com/mailru/easyjson"
+)
+
+// The handlers of chromedp process the page and DOM events only, and drop the others.
+// The events of a target are received by a session, a second DevTools connection to the target,
+// which enables the domains of the events listened to. The commands whose effects depend on
+// the session, e.g. Fetch.enable and Runtime.addBinding, are sent on it too.
+
+// sessionStore is the sessions of the targets of a browser, shared by the Puppets driving them.
+type sessionStore struct {
+	// ctx is the context of the browser, the sessions are closed with it.
+	ctx context.Context
+
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+// session is a DevTools connection to a target, it implements cdp.Executor.
+type session struct {
+	conn client.Transport
+
+	mu        sync.Mutex
+	last      int64
+	res       map[int64]chan *cdproto.Message
+	listeners map[*listener]struct{}
+	// domains are the reference counts of the domains enabled by the listeners.
+	domains map[string]int
+	// kept are the domains kept enabled for the lifetime of the session.
+	kept   map[string]bool
+	closed bool
+	done   chan struct{}
+}
+
+// listener receives the events of the types in order, in its own goroutine.
+type listener struct {
+	types map[cdproto.MethodType]bool
+	fn    func(ev interface{})
+	// domains are the domains enabled for the listener, released once it is removed.
+	domains []string
+
+	mu sync.Mutex
+	// queue is unbounded, so a slow listener never blocks the session.
+	queue   []interface{}
+	stopped bool
+	signal  chan struct{}
+	done    chan struct{}
+}
+
+// manualDomains are the domains without an enable command, or enabled with parameters by their users.
+var manualDomains = map[string]bool{
+	"Fetch":  true,
+	"Target": true,
+}
+
+// storedSessions returns the sessions of the targets of the browser.
+func (c *Puppet) storedSessions() *sessionStore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sessions == nil {
+		c.sessions = &sessionStore{ctx: c.ctx}
+	}
+	return c.sessions
+}
+
+// session returns the session of the target the Puppet is bound to by Tab, or else of the active target.
+func (c *Puppet) session() (*session, error) {
+	id, err := c.currentTarget()
+	if err != nil {
+		return nil, err
+	}
+	return c.sessionByID(id)
+}
+
+// sessionOf returns the session of the target of the handler.
+func (c *Puppet) sessionOf(h cdp.Executor) (*session, error) {
+	if s, ok := h.(*session); ok {
+		return s, nil
+	}
+	id, err := c.targetOf(h)
+	if err != nil {
+		return nil, err
+	}
+	return c.sessionByID(id)
+}
+
+// targetOf returns the id of the target of the handler.
+func (c *Puppet) targetOf(h cdp.Executor) (string, error) {
+	for _, id := range c.cdp.ListTargets() {
+		if c.cdp.GetHandlerByID(id) == h {
+			return id, nil
+		}
+	}
+	return "", errors.New("no target for the handler")
+}
+
+// sessionByID returns the session of the target with the specified id, connecting to it if needed.
+func (c *Puppet) sessionByID(id string) (*session, error) {
+	store := c.storedSessions()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if s := store.sessions[id]; s != nil && !s.isClosed() {
+		return s, nil
+	}
+	targets, err := c.cli.ListTargets(store.ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if t.GetID() != id {
+			continue
+		}
+		conn, err := client.Dial(t.GetWebsocketURL())
+		if err != nil {
+			return nil, err
+		}
+		s := newSession(store.ctx, conn)
+		if store.sessions == nil {
+			store.sessions = map[string]*session{}
+		}
+		store.sessions[id] = s
+		return s, nil
+	}
+	return nil, &actionError{kind: ErrTargetNotFound, err: fmt.Errorf("target %q not found", id)}
+}
+
+// newSession starts reading the connection until it or the context is closed.
+func newSession(ctx context.Context, conn client.Transport) *session {
+	s := &session{
+		conn:      conn,
+		res:       map[int64]chan *cdproto.Message{},
+		listeners: map[*listener]struct{}{},
+		domains:   map[string]int{},
+		kept:      map[string]bool{},
+		done:      make(chan struct{}),
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.done:
+		}
+		conn.Close()
+	}()
+	go s.read()
+	return s
+}
+
+func (s *session) read() {
+	defer s.close()
+	for {
+		buf, err := s.conn.Read()
+		if err != nil {
+			return
+		}
+		msg := &cdproto.Message{}
+		err = json.Unmarshal(buf, msg)
+		if err != nil {
+			continue
+		}
+		if msg.Method == "" {
+			s.mu.Lock()
+			ch := s.res[msg.ID]
+			delete(s.res, msg.ID)
+			s.mu.Unlock()
+			if ch != nil {
+				ch <- msg
+			}
+			continue
+		}
+		s.dispatch(msg)
+	}
+}
+
+// dispatch queues the event to the listeners of its type.
+func (s *session) dispatch(msg *cdproto.Message) {
+	s.mu.Lock()
+	var ls []*listener
+	for l := range s.listeners {
+		if l.types[msg.Method] {
+			ls = append(ls, l)
+		}
+	}
+	s.mu.Unlock()
+	if len(ls) == 0 {
+		return
+	}
+	ev, err := unmarshalEvent(msg)
+	if err != nil {
+		return
+	}
+	for _, l := range ls {
+		l.push(ev)
+	}
+}
+
+// unmarshalEvent decodes the event, including the events unknown to cdproto.
+func unmarshalEvent(msg *cdproto.Message) (interface{}, error) {
+	if newEvent, ok := localEvents[msg.Method]; ok {
+		ev := newEvent()
+		err := json.Unmarshal(msg.Params, ev)
+		if err != nil {
+			return nil, err
+		}
+		return ev, nil
+	}
+	return cdproto.UnmarshalMessage(msg)
+}
+
+// localEvents are the constructors of the events unknown to cdproto, or with fields it lacks.
+var localEvents = map[cdproto.MethodType]func() interface{}{
+	cdproto.EventFetchRequestPaused:       func() interface{} { return &requestPaused{} },
+	cdproto.EventNetworkRequestWillBeSent: func() interface{} { return &requestWillBeSent{} },
+	eventPreloadPrerenderStatusUpdated:    func() interface{} { return &prerenderStatusUpdated{} },
+	eventPageBackForwardCacheNotUsed:      func() interface{} { return &backForwardCacheNotUsed{} },
+}
+
+func (s *session) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+	for id, ch := range s.res {
+		close(ch)
+		delete(s.res, id)
+	}
+	for l := range s.listeners {
+		l.stop()
+	}
+}
+
+func (s *session) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Execute executes the command on the session, it implements cdp.Executor.
+func (s *session) Execute(ctx context.Context, method string, params json.Marshaler, res json.Unmarshaler) error {
+	buf := easyjson.RawMessage(`{}`)
+	if params != nil {
+		var err error
+		buf, err = json.Marshal(params)
+		if err != nil {
+			return err
+		}
+	}
+
+	ch := make(chan *cdproto.Message, 1)
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return chromedp.ErrChannelClosed
+	}
+	s.last++
+	id := s.last
+	s.res[id] = ch
+	s.mu.Unlock()
+
+	data, err := json.Marshal(&cdproto.Message{
+		ID:     id,
+		Method: cdproto.MethodType(method),
+		Params: buf,
+	})
+	if err == nil {
+		s.mu.Lock()
+		err = s.conn.Write(data)
+		s.mu.Unlock()
+	}
+	if err != nil {
+		s.mu.Lock()
+		delete(s.res, id)
+		s.mu.Unlock()
+		return err
+	}
+
+	select {
+	case msg, ok := <-ch:
+		switch {
+		case !ok:
+			return chromedp.ErrChannelClosed
+		case msg.Error != nil:
+			return msg.Error
+		case res != nil:
+			return json.Unmarshal(msg.Result, res)
+		}
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		delete(s.res, id)
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// sync returns once the events sent by the browser before the call are queued to the listeners.
+func (s *session) sync(ctx context.Context) error {
+	return s.Execute(ctx, "Page.getFrameTree", nil, nil)
+}
+
+// add adds the listener for the events of the types, enabling their domains.
+func (s *session) add(ctx context.Context, fn func(ev interface{}), types ...cdproto.MethodType) (l *listener, err error) {
+	l = &listener{
+		types:  map[cdproto.MethodType]bool{},
+		fn:     fn,
+		signal: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	domains := map[string]bool{}
+	for _, t := range types {
+		l.types[t] = true
+		if d := t.Domain(); !manualDomains[d] {
+			domains[d] = true
+		}
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, chromedp.ErrChannelClosed
+	}
+	s.listeners[l] = struct{}{}
+	s.mu.Unlock()
+	go l.run()
+
+	for d := range domains {
+		err = s.acquire(ctx, d)
+		if err != nil {
+			s.remove(l)
+			return nil, err
+		}
+		l.domains = append(l.domains, d)
+	}
+	return l, nil
+}
+
+// remove removes the listener, the events already queued are still delivered,
+// and releases the domains it enabled.
+func (s *session) remove(l *listener) {
+	s.mu.Lock()
+	delete(s.listeners, l)
+	s.mu.Unlock()
+	l.stop()
+	for _, d := range l.domains {
+		s.release(d)
+	}
+}
+
+// acquire enables the domain if no one else did.
+func (s *session) acquire(ctx context.Context, domain string) error {
+	s.mu.Lock()
+	s.domains[domain]++
+	first := s.domains[domain] == 1
+	s.mu.Unlock()
+	if !first {
+		return nil
+	}
+	err := s.Execute(ctx, domain+".enable", nil, nil)
+	if err != nil {
+		s.mu.Lock()
+		s.domains[domain]--
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// keep enables the domain for the lifetime of the session.
+func (s *session) keep(ctx context.Context, domain string) error {
+	s.mu.Lock()
+	kept := s.kept[domain]
+	s.kept[domain] = true
+	s.mu.Unlock()
+	if kept {
+		return nil
+	}
+	err := s.acquire(ctx, domain)
+	if err != nil {
+		s.mu.Lock()
+		delete(s.kept, domain)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// release disables the domain once no one uses it.
+func (s *session) release(domain string) {
+	s.mu.Lock()
+	s.domains[domain]--
+	last := s.domains[domain] == 0
+	if last {
+		delete(s.domains, domain)
+	}
+	closed := s.closed
+	s.mu.Unlock()
+	if last && !closed {
+		go s.Execute(context.Background(), domain+".disable", nil, nil)
+	}
+}
+
+func (l *listener) push(ev interface{}) {
+	l.mu.Lock()
+	if l.stopped {
+		l.mu.Unlock()
+		return
+	}
+	l.queue = append(l.queue, ev)
+	l.mu.Unlock()
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+}
+
+// stop stops queuing events, the listener exits once the events queued are delivered.
+func (l *listener) stop() {
+	l.mu.Lock()
+	l.stopped = true
+	l.mu.Unlock()
+	select {
+	case l.signal <- struct{}{}:
+	default:
+	}
+}
+
+// wait waits until the listener has exited.
+func (l *listener) wait() {
+	<-l.done
+}
+
+func (l *listener) run() {
+	defer close(l.done)
+	for {
+		l.mu.Lock()
+		queue := l.queue
+		l.queue = nil
+		stopped := l.stopped
+		l.mu.Unlock()
+		for _, ev := range queue {
+			l.fn(ev)
+		}
+		if len(queue) != 0 {
+			continue
+		}
+		if stopped {
+			return
+		}
+		<-l.signal
+	}
+}
+
+// listen calls fn with every event of the types fired by the target the Puppet is bound to by Tab,
+// or else by the active target, until the Puppet is closed. The commands of fn run on the session of the target.
+func (c *Puppet) listen(fn func(ctx context.Context, h cdp.Executor, ev interface{}), types ...cdproto.MethodType) (err error) {
+	s, err := c.session()
+	if err != nil {
+		return err
+	}
+	ctx := c.ctx
+	l, err := s.add(ctx, func(ev interface{}) {
+		fn(ctx, s, ev)
+	}, types...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-l.done:
+		}
+		s.remove(l)
+	}()
+	return nil
+}
+
+// subscribe returns a channel receiving the events of the types fired by the target the Puppet is bound to by Tab,
+// or else by the active target, release must be called once the events are no longer needed.
+func (c *Puppet) subscribe(types ...cdproto.MethodType) (ch <-chan interface{}, release func(), err error) {
+	s, err := c.session()
+	if err != nil {
+		return nil, nil, err
+	}
+	out := make(chan interface{})
+	stop := make(chan struct{})
+	l, err := s.add(c.ctx, func(ev interface{}) {
+		select {
+		case out <- ev:
+		case <-stop:
+		}
+	}, types...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			close(stop)
+			s.remove(l)
+		})
+	}
+	return out, release, nil
+}
+
+// runSession runs the actions on the session of the target the Puppet is bound to by Tab, or else of the active target,
+// for the commands whose effects depend on the session they are sent on.
+func (c *Puppet) runSession(actions ...chromedp.Action) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		s, err := c.sessionOf(h)
+		if err != nil {
+			return err
+		}
+		for _, action := range actions {
+			err = action.Do(ctx, s)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// withNetwork returns an action running the network configuration action on the session of the target,
+// keeping the Network domain enabled on it, as the configuration applies only while the domain is enabled.
+func (c *Puppet) withNetwork(action chromedp.Action) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		s, err := c.sessionOf(h)
+		if err != nil {
+			return err
+		}
+		err = s.keep(ctx, "Network")
+		if err != nil {
+			return err
+		}
+		return action.Do(ctx, s)
+	})
+}
@@ -0,0 +1,55 @@
+package puppet
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+)
+
+// EventSourceMessage is a message received by an EventSource of a page.
+type EventSourceMessage struct {
+	// URL is the url of the EventSource.
+	URL string
+	// Event is the type of the event, "message" if the server did not set it.
+	Event string
+	ID    string
+	Data  string
+	Time  time.Time
+}
+
+// OnEventSourceMessage calls fn with every message received by the EventSources of the current target.
+func (c *Puppet) OnEventSourceMessage(fn func(*EventSourceMessage)) (err error) {
+	urls := map[network.RequestID]string{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *requestWillBeSent:
+			if ev.Type == network.ResourceTypeEventSource {
+				urls[ev.RequestID] = ev.Request.URL
+			}
+		case *network.EventLoadingFinished:
+			delete(urls, ev.RequestID)
+		case *network.EventLoadingFailed:
+			delete(urls, ev.RequestID)
+		case *network.EventEventSourceMessageReceived:
+			event := ev.EventName
+			if event == "" {
+				event = "message"
+			}
+			fn(&EventSourceMessage{
+				URL:   urls[ev.RequestID],
+				Event: event,
+				ID:    ev.EventID,
+				Data:  ev.Data,
+				Time:  time.Now(),
+			})
+		}
+	},
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkLoadingFailed,
+		cdproto.EventNetworkEventSourceMessageReceived,
+	)
+}
@@ -0,0 +1,30 @@
+package puppet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnEventSourceMessage(t *testing.T) {
+	p, b := newFakePuppet(t)
+	messages := make(chan *EventSourceMessage, 1)
+	err := p.OnEventSourceMessage(func(m *EventSourceMessage) {
+		messages <- m
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Network.requestWillBeSent", `{"requestId":"1","loaderId":"L","documentURL":"https://example.com/","type":"EventSource","request":{"url":"https://example.com/events","method":"GET","headers":{}}}`)
+	b.emit("Network.eventSourceMessageReceived", `{"requestId":"1","eventName":"","eventId":"7","data":"hello"}`)
+
+	var m *EventSourceMessage
+	select {
+	case m = <-messages:
+	case <-time.After(time.Second):
+		t.Fatal("message not received in time")
+	}
+	if m.URL != "https://example.com/events" || m.Event != "message" || m.ID != "7" || m.Data != "hello" {
+		t.Errorf("got message %+v", m)
+	}
+}
@@ -0,0 +1,178 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/log"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// consoleSize is the number of the most recent console messages kept.
+const consoleSize = 200
+
+// ConsoleMessage is a message logged to the console of a page, or an uncaught exception.
+type ConsoleMessage struct {
+	// Level is the level of the message, e.g. "log", "warning", "error" or "exception".
+	Level string
+	Text  string
+	URL   string
+	Line  int64
+	Time  time.Time
+}
+
+// CaptureOnFailure makes every failed action capture a screenshot, the HTML and the url of the current page,
+// and the recent console messages into the store, e.g. DirStore("artifacts"), the location of the artifacts
+// is appended to the error of the action. Console messages are collected from now on.
+func (c *Puppet) CaptureOnFailure(store ArtifactStore) (err error) {
+	err = c.collectConsole()
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.failureStore = store
+	c.mu.Unlock()
+	return nil
+}
+
+// ConsoleMessages returns the recent console messages of the current target, once collected by CaptureOnFailure.
+func (c *Puppet) ConsoleMessages() []ConsoleMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ConsoleMessage(nil), c.console...)
+}
+
+func (c *Puppet) collectConsole() (err error) {
+	c.mu.Lock()
+	collecting := c.collectingConsole
+	c.collectingConsole = true
+	c.mu.Unlock()
+	if collecting {
+		return nil
+	}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		var msg ConsoleMessage
+		switch ev := ev.(type) {
+		case *runtime.EventConsoleAPICalled:
+			args := make([]string, 0, len(ev.Args))
+			for _, arg := range ev.Args {
+				switch {
+				case arg.Value != nil:
+					args = append(args, strings.Trim(string(arg.Value), `"`))
+				case arg.Description != "":
+					args = append(args, arg.Description)
+				default:
+					args = append(args, arg.Type.String())
+				}
+			}
+			msg = ConsoleMessage{
+				Level: ev.Type.String(),
+				Text:  strings.Join(args, " "),
+			}
+			if ev.StackTrace != nil && len(ev.StackTrace.CallFrames) != 0 {
+				msg.URL = ev.StackTrace.CallFrames[0].URL
+				msg.Line = ev.StackTrace.CallFrames[0].LineNumber
+			}
+		case *runtime.EventExceptionThrown:
+			msg = ConsoleMessage{
+				Level: "exception",
+				Text:  ev.ExceptionDetails.Text,
+				URL:   ev.ExceptionDetails.URL,
+				Line:  ev.ExceptionDetails.LineNumber,
+			}
+			if ev.ExceptionDetails.Exception != nil && ev.ExceptionDetails.Exception.Description != "" {
+				msg.Text = ev.ExceptionDetails.Exception.Description
+			}
+		case *log.EventEntryAdded:
+			msg = ConsoleMessage{
+				Level: ev.Entry.Level.String(),
+				Text:  ev.Entry.Text,
+				URL:   ev.Entry.URL,
+				Line:  ev.Entry.LineNumber,
+			}
+		default:
+			return
+		}
+		msg.Time = time.Now()
+		c.mu.Lock()
+		c.console = append(c.console, msg)
+		if len(c.console) > consoleSize {
+			c.console = c.console[len(c.console)-consoleSize:]
+		}
+		c.mu.Unlock()
+	},
+		cdproto.EventRuntimeConsoleAPICalled,
+		cdproto.EventRuntimeExceptionThrown,
+		cdproto.EventLogEntryAdded,
+	)
+}
+
+// captureFailure stores the artifacts of the current page into the failure store,
+// and returns the error with their location.
+func (c *Puppet) captureFailure(name string, failure error) error {
+	c.mu.Lock()
+	store := c.failureStore
+	if store == nil || c.capturingFailure || c.ctx.Err() != nil {
+		c.mu.Unlock()
+		return failure
+	}
+	c.capturingFailure = true
+	console := append([]ConsoleMessage(nil), c.console...)
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.capturingFailure = false
+		c.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(c.ctx, 10*time.Second)
+	defer cancel()
+
+	if name == "" {
+		name = "action"
+	}
+	prefix := time.Now().Format("20060102-150405.000") + "-" + name + "/"
+
+	var url, html string
+	var shot []byte
+	c.runOnce(ctx, chromedp.Tasks{
+		chromedp.Location(&url),
+		chromedp.OuterHTML("html", &html, chromedp.ByQuery),
+	})
+	c.runOnce(ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) (err error) {
+		shot, err = page.CaptureScreenshot().
+			Do(ctx, h)
+		return err
+	}))
+
+	var sb strings.Builder
+	for _, msg := range console {
+		fmt.Fprintf(&sb, "%s [%s] %s", msg.Time.Format(time.RFC3339Nano), msg.Level, msg.Text)
+		if msg.URL != "" {
+			fmt.Fprintf(&sb, " (%s:%d)", msg.URL, msg.Line)
+		}
+		sb.WriteString("\n")
+	}
+
+	location, err := store.Put(prefix+"error.txt", "text/plain", []byte(fmt.Sprintf("action: %s\nurl: %s\nerror: %v\n", name, url, failure)))
+	if err != nil {
+		return failure
+	}
+	if len(shot) != 0 {
+		store.Put(prefix+"screenshot.png", "image/png", shot)
+	}
+	if html != "" {
+		store.Put(prefix+"page.html", "text/html", []byte(html))
+	}
+	store.Put(prefix+"console.txt", "text/plain", []byte(sb.String()))
+	if c.logger != nil {
+		c.logger.Log(LogInfo, "failure captured", "action", name, "location", location)
+	}
+	return fmt.Errorf("%w (artifacts: %s)", failure, strings.TrimSuffix(location, "error.txt"))
+}
@@ -0,0 +1,232 @@
+package puppet
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// fakeBrowser is a DevTools endpoint with a single page target, answering the commands
+// chromedp needs to attach to it, and firing the events of a domain to the connections which enabled it.
+type fakeBrowser struct {
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	conns   map[*fakeConn]struct{}
+	results map[string]string
+	calls   []string
+	params  map[string]string
+	// hooks are called before replying to the calls of the methods.
+	hooks map[string]func()
+}
+
+type fakeConn struct {
+	mu       sync.Mutex
+	ws       *websocket.Conn
+	enabled  map[string]bool
+	bindings map[string]bool
+}
+
+const fakeFrameTree = `{"frameTree":{"frame":{"id":"F","loaderId":"L","url":"about:blank","securityOrigin":"null","mimeType":"text/html"}}}`
+
+func newFakeBrowser(t *testing.T) *fakeBrowser {
+	b := &fakeBrowser{
+		conns:  map[*fakeConn]struct{}{},
+		params: map[string]string{},
+		hooks:  map[string]func(){},
+		results: map[string]string{
+			"Page.getResourceTree": fakeFrameTree,
+			"Page.getFrameTree":    fakeFrameTree,
+			"DOM.getDocument":      `{"root":{"nodeId":1,"backendNodeId":1,"nodeType":9,"nodeName":"#document","localName":"","nodeValue":""}}`,
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/json/version", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Browser":"Chrome/72.0.3626.109","Protocol-Version":"1.3"}`)
+	})
+	mux.HandleFunc("/json/list", func(w http.ResponseWriter, r *http.Request) {
+		ws := "ws" + strings.TrimPrefix(b.srv.URL, "http") + "/devtools/page/T"
+		fmt.Fprintf(w, `[{"id":"T","type":"page","url":"about:blank","webSocketDebuggerUrl":%q}]`, ws)
+	})
+	mux.HandleFunc("/devtools/page/T", b.serve)
+	b.srv = httptest.NewServer(mux)
+	t.Cleanup(b.close)
+	return b
+}
+
+// URL returns the DevTools HTTP endpoint of the browser.
+func (b *fakeBrowser) URL() string {
+	return b.srv.URL + "/json"
+}
+
+func (b *fakeBrowser) close() {
+	b.mu.Lock()
+	for c := range b.conns {
+		c.ws.Close()
+	}
+	b.mu.Unlock()
+	b.srv.Close()
+}
+
+func (b *fakeBrowser) serve(w http.ResponseWriter, r *http.Request) {
+	ws, err := b.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &fakeConn{
+		ws:       ws,
+		enabled:  map[string]bool{},
+		bindings: map[string]bool{},
+	}
+	b.mu.Lock()
+	b.conns[c] = struct{}{}
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		b.mu.Unlock()
+		ws.Close()
+	}()
+
+	for {
+		var msg struct {
+			ID     int64           `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+		}
+		err := ws.ReadJSON(&msg)
+		if err != nil {
+			return
+		}
+		var params struct {
+			Name string `json:"name"`
+		}
+		json.Unmarshal(msg.Params, &params)
+		b.mu.Lock()
+		b.calls = append(b.calls, msg.Method)
+		b.params[msg.Method] = string(msg.Params)
+		result, ok := b.results[msg.Method]
+		hook := b.hooks[msg.Method]
+		b.mu.Unlock()
+		if hook != nil {
+			hook()
+		}
+		if !ok {
+			result = `{}`
+		}
+		c.mu.Lock()
+		switch domain := strings.Split(msg.Method, ".")[0]; {
+		case strings.HasSuffix(msg.Method, ".enable"):
+			c.enabled[domain] = true
+		case strings.HasSuffix(msg.Method, ".disable"):
+			delete(c.enabled, domain)
+		case msg.Method == "Runtime.addBinding":
+			c.bindings[params.Name] = true
+		}
+		err = ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"id":%d,"result":%s}`, msg.ID, result)))
+		c.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// emit fires the event to the connections which enabled its domain, and returns their number.
+func (b *fakeBrowser) emit(method string, params string) int {
+	domain := strings.Split(method, ".")[0]
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for c := range b.conns {
+		c.mu.Lock()
+		if c.enabled[domain] {
+			c.ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"method":%q,"params":%s}`, method, params)))
+			n++
+		}
+		c.mu.Unlock()
+	}
+	return n
+}
+
+// callBinding calls the binding with the payload on the connections which added it, and returns their number.
+func (b *fakeBrowser) callBinding(name, payload string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for c := range b.conns {
+		c.mu.Lock()
+		if c.bindings[name] && c.enabled["Runtime"] {
+			c.ws.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf(`{"method":"Runtime.bindingCalled","params":{"name":%q,"payload":%q,"executionContextId":1}}`, name, payload)))
+			n++
+		}
+		c.mu.Unlock()
+	}
+	return n
+}
+
+// enabled returns the number of connections which enabled the domain.
+func (b *fakeBrowser) enabled(domain string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for c := range b.conns {
+		c.mu.Lock()
+		if c.enabled[domain] {
+			n++
+		}
+		c.mu.Unlock()
+	}
+	return n
+}
+
+// called returns the number of times the method was called.
+func (b *fakeBrowser) called(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, m := range b.calls {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
+
+// lastParams returns the params of the last call of the method.
+func (b *fakeBrowser) lastParams(method string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.params[method]
+}
+
+// newFakePuppet returns a Puppet attached to a fake browser.
+func newFakePuppet(t *testing.T) (*Puppet, *fakeBrowser) {
+	b := newFakeBrowser(t)
+	p, err := NewPuppet(b.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		p.Close()
+	})
+	return p, b
+}
+
+// eventually fails the test unless cond is true within a second.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Second / 100)
+	}
+}
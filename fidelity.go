@@ -0,0 +1,76 @@
+package puppet
+
+import (
+	"bytes"
+	"image/png"
+	"net"
+	"net/http"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Fidelity is how faithfully an archive renders compared to the original page.
+type Fidelity struct {
+	// Score is the ratio of the pixels rendered as in the original, from 0 to 1.
+	Score float64
+	// DiffPixels is the number of differing pixels.
+	DiffPixels int
+	// Diff is the PNG image of the differing pixels in red.
+	Diff []byte
+}
+
+// ArchiveFidelity serves the MHTML or WARC archive locally with ServeArchive, loads it into the current target,
+// and compares its screenshot with the PNG screenshot of the original page taken with the same viewport.
+func (c *Puppet) ArchiveFidelity(archive []byte, original []byte) (fidelity *Fidelity, err error) {
+	want, err := png.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	err = ServeArchive(bytes.NewReader(archive), mux)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	err = c.Navigate("http://" + listener.Addr().String() + "/")
+	if err != nil {
+		return nil, err
+	}
+	var ok bool
+	err = c.run(c.ctx,
+		chromedp.Evaluate(waitRendered, &ok, awaitPromise))
+	if err != nil {
+		return nil, err
+	}
+	shot, err := c.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	got, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		return nil, err
+	}
+
+	out, diffPixels, total := diffImages(got, want, 0.1, nil)
+	var buf bytes.Buffer
+	err = png.Encode(&buf, out)
+	if err != nil {
+		return nil, err
+	}
+	fidelity = &Fidelity{
+		DiffPixels: diffPixels,
+		Diff:       buf.Bytes(),
+	}
+	if total > 0 {
+		fidelity.Score = 1 - float64(diffPixels)/float64(total)
+	}
+	return fidelity, nil
+}
@@ -0,0 +1,94 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// ActiveElement returns the element of the current page having the focus, nil if none has.
+func (c *Puppet) ActiveElement() (el *ElementInfo, err error) {
+	return el, c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	var el = document.activeElement;
+	if (!el || el === document.body || el === document.documentElement) {
+		return null;
+	}
+	return (`+describeElement+`)(el);
+})()`, &el))
+}
+
+// Blur removes the focus from the first node matching the selector.
+func (c *Puppet) Blur(sel string) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		callOn(sel, `function () {
+	this.blur();
+}`, nil))
+}
+
+// FocusChange is a change of the focus of a page.
+type FocusChange struct {
+	// Type is "focus" when the element gained the focus, "blur" when it lost it.
+	Type    string      `json:"type"`
+	Element ElementInfo `json:"element"`
+}
+
+const focusBinding = "__puppetFocusChange"
+
+const focusListener = `(function () {
+	if (window.__puppetFocusListener) {
+		return;
+	}
+	window.__puppetFocusListener = true;
+	var describe = ` + describeElement + `;
+	["focusin", "focusout"].forEach(function (type) {
+		document.addEventListener(type, function (e) {
+			if (!e.target || e.target.nodeType !== 1) {
+				return;
+			}
+			window.` + focusBinding + `(JSON.stringify({
+				type: type === "focusin" ? "focus" : "blur",
+				element: describe(e.target)
+			}));
+		}, true);
+	});
+})()`
+
+// OnFocusChange calls fn with every change of the focus of the current target, e.g. to check focus traps of modals.
+func (c *Puppet) OnFocusChange(fn func(FocusChange)) (err error) {
+	err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		called, ok := ev.(*runtime.EventBindingCalled)
+		if !ok || called.Name != focusBinding {
+			return
+		}
+		var change FocusChange
+		if json.Unmarshal([]byte(called.Payload), &change) != nil {
+			return
+		}
+		fn(change)
+	}, cdproto.EventRuntimeBindingCalled)
+	if err != nil {
+		return err
+	}
+
+	// the binding calls are reported to the session which added the binding
+	err = c.runSession(runtime.AddBinding(focusBinding))
+	if err != nil {
+		return err
+	}
+	var ok bool
+	return c.run(c.ctx, chromedp.Tasks{
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(focusListener).
+				Do(ctx, h)
+			return err
+		}),
+		chromedp.Evaluate(focusListener+` || true`, &ok),
+	})
+}
@@ -0,0 +1,201 @@
+package puppet
+
+import (
+	"fmt"
+	"html"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/runner"
+)
+
+// WithFontDirs makes the fonts of the directories available to the launched browser in addition to the fonts
+// of the system, by a fontconfig configuration, e.g. to render CJK or emoji in minimal containers.
+func WithFontDirs(dirs ...string) Option {
+	return func(o *options) {
+		fontconfig(o, dirs, "")
+	}
+}
+
+// emojiFonts are the color emoji fonts searched by WithEmoji, in order of preference.
+var emojiFonts = []string{
+	"Noto Color Emoji",
+	"Apple Color Emoji",
+	"Segoe UI Emoji",
+	"Twemoji",
+	"JoyPixels",
+}
+
+// emojiFontFiles are the files of the color emoji fonts in the common font directories.
+var emojiFontFiles = []string{
+	"/usr/share/fonts/truetype/noto/NotoColorEmoji.ttf",
+	"/usr/share/fonts/noto/NotoColorEmoji.ttf",
+	"/usr/share/fonts/google-noto-color-emoji/NotoColorEmoji.ttf",
+	"/usr/share/fonts/noto-emoji/NotoColorEmoji.ttf",
+	"/usr/share/fonts/TTF/NotoColorEmoji.ttf",
+	"/usr/share/fonts/twemoji/Twemoji.ttf",
+	"/System/Library/Fonts/Apple Color Emoji.ttc",
+}
+
+// WithEmoji makes the launched browser render emoji with a color emoji font, as users see them,
+// e.g. for social media cards. The fonts of the directories are added, and NewPuppet fails
+// if no color emoji font is found in them nor in the common font directories of the system.
+func WithEmoji(dirs ...string) Option {
+	return func(o *options) {
+		if o.err != nil {
+			return
+		}
+		if !hasEmojiFont(dirs) {
+			o.err = fmt.Errorf("fonts: no color emoji font found, install e.g. fonts-noto-color-emoji")
+			return
+		}
+		var sb strings.Builder
+		for _, family := range []string{"emoji", "sans-serif", "serif", "monospace", "system-ui"} {
+			relation := "accept"
+			if family == "emoji" {
+				relation = "prefer"
+			}
+			fmt.Fprintf(&sb, "\t<alias>\n\t\t<family>%s</family>\n\t\t<%s>\n", family, relation)
+			for _, font := range emojiFonts {
+				fmt.Fprintf(&sb, "\t\t\t<family>%s</family>\n", font)
+			}
+			fmt.Fprintf(&sb, "\t\t</%s>\n\t</alias>\n", relation)
+		}
+		fontconfig(o, dirs, sb.String())
+		o.flags = append(o.flags, runner.Flag("font-render-hinting", "none"))
+	}
+}
+
+func hasEmojiFont(dirs []string) bool {
+	for _, file := range emojiFontFiles {
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+	found := false
+	for _, dir := range dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() && strings.Contains(strings.ToLower(info.Name()), "emoji") {
+				found = true
+			}
+			return nil
+		})
+	}
+	return found
+}
+
+// fontconfig makes the launched browser use a fontconfig configuration including the one of the system,
+// the fonts of the directories and the extra elements.
+func fontconfig(o *options, dirs []string, extra string) {
+	if o.err != nil {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0"?>
+<!DOCTYPE fontconfig SYSTEM "fonts.dtd">
+<fontconfig>
+	<include ignore_missing="yes">/etc/fonts/fonts.conf</include>
+`)
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			o.err = fmt.Errorf("fonts: %v", err)
+			return
+		}
+		fmt.Fprintf(&sb, "\t<dir>%s</dir>\n", html.EscapeString(abs))
+	}
+	sb.WriteString(extra)
+	sb.WriteString("</fontconfig>\n")
+
+	dir, err := ioutil.TempDir("", "puppet-fonts")
+	if err != nil {
+		o.err = fmt.Errorf("fonts: %v", err)
+		return
+	}
+	conf := filepath.Join(dir, "fonts.conf")
+	err = ioutil.WriteFile(conf, []byte(sb.String()), 0644)
+	if err != nil {
+		os.RemoveAll(dir)
+		o.err = fmt.Errorf("fonts: %v", err)
+		return
+	}
+	o.flags = append(o.flags, runner.CmdOpt(func(cmd *exec.Cmd) error {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "FONTCONFIG_FILE="+conf)
+		return nil
+	}))
+	o.cleanups = append(o.cleanups, func() {
+		os.RemoveAll(dir)
+	})
+}
+
+// MissingGlyph is a character of the page rendered as tofu, the box of a missing glyph.
+type MissingGlyph struct {
+	Char string `json:"char"`
+	// Font is the font family of the text the character appears in.
+	Font string `json:"font"`
+	// Count is the number of text nodes the character appears in with the font.
+	Count int `json:"count"`
+}
+
+// MissingGlyphs returns the characters of the visible text of the current page that none of the fonts available
+// can render. Each character is drawn with the font of its text, and compared with a character no font has.
+func (c *Puppet) MissingGlyphs() (glyphs []MissingGlyph, err error) {
+	return glyphs, c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	var canvas = document.createElement("canvas");
+	canvas.width = canvas.height = 32;
+	var ctx = canvas.getContext("2d");
+	function render(ch, font) {
+		ctx.clearRect(0, 0, 32, 32);
+		ctx.font = "20px " + font;
+		ctx.textBaseline = "top";
+		ctx.fillStyle = "#000";
+		ctx.fillText(ch, 4, 4);
+		return ctx.getImageData(0, 0, 32, 32).data.join(",");
+	}
+	var tofu = {};
+	var checked = {};
+	var counts = {};
+	var walker = document.createTreeWalker(document.body || document.documentElement, NodeFilter.SHOW_TEXT);
+	for (var node = walker.nextNode(); node; node = walker.nextNode()) {
+		var el = node.parentElement;
+		if (!el || !node.textContent.trim() || /^(SCRIPT|STYLE|NOSCRIPT|TEMPLATE)$/.test(el.tagName)) {
+			continue;
+		}
+		var style = window.getComputedStyle(el);
+		if (style.display === "none" || style.visibility === "hidden") {
+			continue;
+		}
+		var font = style.fontFamily;
+		if (!(font in tofu)) {
+			// U+FFFF is a noncharacter, no font has a glyph for it
+			tofu[font] = render("\uffff", font);
+		}
+		var seen = {};
+		Array.from(node.textContent).forEach(function (ch) {
+			if (/\s/.test(ch) || seen[ch] || ch.charCodeAt(0) < 0x20) {
+				return;
+			}
+			seen[ch] = true;
+			var key = font + "\u0000" + ch;
+			if (!(key in checked)) {
+				checked[key] = render(ch, font) === tofu[font];
+			}
+			if (checked[key]) {
+				counts[key] = (counts[key] || 0) + 1;
+			}
+		});
+	}
+	return Object.keys(counts).map(function (key) {
+		var i = key.indexOf("\u0000");
+		return { char: key.slice(i + 1), font: key.slice(0, i), count: counts[key] };
+	});
+})()`, &glyphs))
+}
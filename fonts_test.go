@@ -0,0 +1,44 @@
+package puppet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFontDirsRemovedOnError(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	b := newFakeBrowser(t)
+	url := b.URL()
+	b.close()
+
+	_, err := NewPuppet(url, WithFontDirs(t.TempDir()))
+	if err == nil {
+		t.Fatal("expected the error of the connection")
+	}
+	eventually(t, func() bool {
+		return len(tempDirs(t, tmp)) == 0
+	})
+}
+
+func TestEmojiRemovedOnError(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	fonts := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(fonts, "NotoColorEmoji.ttf"), nil, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewPuppet("", WithEmoji(fonts), func(o *options) {
+		o.err = os.ErrInvalid
+	})
+	if err == nil {
+		t.Fatal("expected the error of the option")
+	}
+	if dirs := tempDirs(t, tmp); len(dirs) != 0 {
+		t.Fatalf("fontconfig directories left behind: %v", dirs)
+	}
+}
@@ -0,0 +1,61 @@
+package puppet
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+)
+
+// inFrames is an action that waits for the first node matching the CSS selector in the document
+// or in any of its same-process frames, and calls fn with it.
+// Selectors that are not valid CSS, e.g. XPath, run the fallback against the document instead.
+func inFrames(sel string, fallback chromedp.Action, fn func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		for {
+			root, err := dom.GetDocument().
+				WithDepth(-1).
+				WithPierce(true).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			id, err := dom.QuerySelector(root.NodeID, sel).
+				Do(ctx, h)
+			if err != nil {
+				return fallback.Do(ctx, h)
+			}
+			if id == 0 {
+				for _, doc := range frameDocuments(root) {
+					id, err = dom.QuerySelector(doc.NodeID, sel).
+						Do(ctx, h)
+					if err == nil && id != 0 {
+						break
+					}
+				}
+			}
+			if id != 0 {
+				return fn(ctx, h, id)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second / 10):
+			}
+		}
+	})
+}
+
+// frameDocuments returns the documents of all frames below the node.
+func frameDocuments(n *cdp.Node) (docs []*cdp.Node) {
+	if n.ContentDocument != nil {
+		docs = append(docs, n.ContentDocument)
+		docs = append(docs, frameDocuments(n.ContentDocument)...)
+	}
+	for _, child := range n.Children {
+		docs = append(docs, frameDocuments(child)...)
+	}
+	return docs
+}
@@ -0,0 +1,32 @@
+module github.com/wzshiming/puppet
+
+go 1.26
+
+require (
+	github.com/chromedp/cdproto v0.0.0-20190217000753-2d8e8962ceb2
+	github.com/chromedp/chromedp v0.1.3
+	github.com/gorilla/websocket v1.4.0
+	github.com/mailru/easyjson v0.0.0-20190221075403-6243d8e04c3f
+	github.com/prometheus/client_golang v1.23.2
+	go.etcd.io/bbolt v1.4.3
+	go.opentelemetry.io/otel v1.38.0
+	go.opentelemetry.io/otel/trace v1.38.0
+	golang.org/x/net v0.47.0
+	golang.org/x/text v0.31.0
+)
+
+require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/disintegration/imaging v1.6.0 // indirect
+	github.com/knq/sysutil v0.0.0-20181215143952-f05b59f0f307 // indirect
+	github.com/munnerz/goautoneg v0.0.0-20191010083416-a7dc8b61c822 // indirect
+	github.com/prometheus/client_model v0.6.2 // indirect
+	github.com/prometheus/common v0.66.1 // indirect
+	github.com/prometheus/procfs v0.16.1 // indirect
+	github.com/rogpeppe/go-internal v1.13.1 // indirect
+	go.yaml.in/yaml/v2 v2.4.2 // indirect
+	golang.org/x/image v0.0.0-20190220214146-31aff87c08e9 // indirect
+	golang.org/x/sys v0.38.0 // indirect
+	google.golang.org/protobuf v1.36.8 // indirect
+)
@@ -0,0 +1,27 @@
+package puppet
+
+import (
+	"net/http"
+)
+
+// ModifyRequestHeaders calls fn to modify the headers of the requests of the current target whose url matches
+// the glob pattern, where * matches any sequence of characters, e.g. "https://api.example.com/*".
+func (c *Puppet) ModifyRequestHeaders(pattern string, fn func(header http.Header)) (err error) {
+	return c.interceptOverride(globRegexp(pattern), func(r *Request) {
+		fn(r.Header)
+		r.Continue()
+	})
+}
+
+// SetRequestHeaders sets the headers of the requests of the current target whose url matches the glob pattern,
+// and removes the headers of the names from them, unlike SetHeaders which applies to all requests.
+func (c *Puppet) SetRequestHeaders(pattern string, set http.Header, remove ...string) (err error) {
+	return c.ModifyRequestHeaders(pattern, func(header http.Header) {
+		for _, name := range remove {
+			header.Del(name)
+		}
+		for k, vs := range set {
+			header[http.CanonicalHeaderKey(k)] = append([]string(nil), vs...)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// nodeFingerprint is what is remembered of the node last matched by a selector, to find it again once the selector breaks.
+type nodeFingerprint struct {
+	Tag   string            `json:"tag"`
+	Text  string            `json:"text"`
+	Attrs map[string]string `json:"attrs"`
+	X     float64           `json:"x"`
+	Y     float64           `json:"y"`
+}
+
+// SetSelectorHealing makes the actions remember the nodes matched by their selectors,
+// and the errors of selectors that stop matching suggest the most similar selectors of the current page,
+// by the text, the attributes and the position of the node. It needs SetActionability to be set,
+// as actions wait for selectors that match nothing otherwise. ValidatePageObject always suggests selectors.
+func (c *Puppet) SetSelectorHealing(enabled bool) {
+	c.mu.Lock()
+	c.healing = enabled
+	c.mu.Unlock()
+}
+
+// SuggestSelectors returns at most n selectors of nodes of the current page most similar to the selector,
+// or to the node it matched last if SetSelectorHealing is enabled, best first.
+func (c *Puppet) SuggestSelectors(sel string, n int) (selectors []string, err error) {
+	sel = c.selector(sel)
+	return selectors, c.run(c.ctx,
+		c.suggestSelectors(sel, n, &selectors))
+}
+
+const fingerprintNode = `function () {
+	var attrs = {};
+	for (var i = 0; i < this.attributes.length; i++) {
+		attrs[this.attributes[i].name] = this.attributes[i].value;
+	}
+	var rect = this.getBoundingClientRect();
+	return {
+		tag: this.tagName.toLowerCase(),
+		text: (this.innerText || this.value || "").trim().slice(0, 200),
+		attrs: attrs,
+		x: rect.left + window.scrollX,
+		y: rect.top + window.scrollY
+	};
+}`
+
+// rememberNode is an action that remembers the first node matching the selector.
+func (c *Puppet) rememberNode(sel string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		var fp nodeFingerprint
+		err := callOn(sel, fingerprintNode, &fp).
+			Do(ctx, h)
+		if err != nil {
+			return nil
+		}
+		c.mu.Lock()
+		if c.fingerprints == nil {
+			c.fingerprints = map[string]*nodeFingerprint{}
+		}
+		c.fingerprints[sel] = &fp
+		c.mu.Unlock()
+		return nil
+	})
+}
+
+const suggestSelectors = `(function (sel, fp, n) {
+	function bigrams(s) {
+		s = (s || "").toLowerCase();
+		var res = {};
+		for (var i = 0; i < s.length - 1; i++) {
+			res[s.substr(i, 2)] = (res[s.substr(i, 2)] || 0) + 1;
+		}
+		return res;
+	}
+	function similarity(a, b) {
+		if (!a || !b) {
+			return 0;
+		}
+		if (a === b) {
+			return 1;
+		}
+		var x = bigrams(a), y = bigrams(b), common = 0, total = 0, k;
+		for (k in x) {
+			total += x[k];
+			if (y[k]) {
+				common += Math.min(x[k], y[k]);
+			}
+		}
+		for (k in y) {
+			total += y[k];
+		}
+		return total ? 2 * common / total : 0;
+	}
+	function escape(s) {
+		return window.CSS && CSS.escape ? CSS.escape(s) : s.replace(/[^\w-]/g, "\\$&");
+	}
+	function unique(s) {
+		try {
+			return document.querySelectorAll(s).length === 1;
+		} catch (e) {
+			return false;
+		}
+	}
+	function selectorOf(el) {
+		var tag = el.tagName.toLowerCase();
+		if (el.id && unique("#" + escape(el.id))) {
+			return "#" + escape(el.id);
+		}
+		var names = ["data-testid", "data-test", "data-qa", "name", "aria-label", "placeholder", "title", "href"];
+		for (var i = 0; i < names.length; i++) {
+			var v = el.getAttribute(names[i]);
+			if (v) {
+				var s = tag + "[" + names[i] + "=" + JSON.stringify(v) + "]";
+				if (unique(s)) {
+					return s;
+				}
+			}
+		}
+		for (var j = 0; j < el.classList.length; j++) {
+			var c = tag + "." + escape(el.classList[j]);
+			if (unique(c)) {
+				return c;
+			}
+		}
+		var path = [];
+		for (var node = el; node && node.nodeType === 1 && node !== document.documentElement; node = node.parentElement) {
+			var part = node.tagName.toLowerCase();
+			if (node.id && unique("#" + escape(node.id))) {
+				path.unshift("#" + escape(node.id));
+				break;
+			}
+			var index = 1;
+			for (var sib = node.previousElementSibling; sib; sib = sib.previousElementSibling) {
+				if (sib.tagName === node.tagName) {
+					index++;
+				}
+			}
+			path.unshift(part + ":nth-of-type(" + index + ")");
+		}
+		return path.join(" > ");
+	}
+
+	var want = { tag: "", ids: [], classes: [], attrs: {} };
+	var last = sel.split(/\s*[\s>+~]\s*/).pop() || "";
+	var m = last.match(/^[a-zA-Z][\w-]*/);
+	if (m) {
+		want.tag = m[0].toLowerCase();
+	}
+	last.replace(/#([\w-]+)/g, function (_, id) { want.ids.push(id); });
+	last.replace(/\.([\w-]+)/g, function (_, c) { want.classes.push(c); });
+	last.replace(/\[([\w-]+)(?:[~|^$*]?=["']?([^"'\]]*)["']?)?\]/g, function (_, k, v) { want.attrs[k] = v || ""; });
+
+	var scored = [];
+	var all = document.body ? document.body.querySelectorAll("*") : [];
+	for (var i = 0; i < all.length; i++) {
+		var el = all[i];
+		var tag = el.tagName.toLowerCase();
+		if (tag === "script" || tag === "style") {
+			continue;
+		}
+		var score = 0;
+		if (want.tag && want.tag === tag) {
+			score += 1;
+		}
+		want.ids.forEach(function (id) {
+			score += 3 * similarity(id, el.id);
+		});
+		want.classes.forEach(function (c) {
+			var best = 0;
+			el.classList.forEach(function (own) {
+				best = Math.max(best, similarity(c, own));
+			});
+			score += 2 * best / want.classes.length;
+		});
+		Object.keys(want.attrs).forEach(function (k) {
+			if (el.hasAttribute(k)) {
+				score += 1 + (want.attrs[k] ? similarity(want.attrs[k], el.getAttribute(k)) : 0);
+			}
+		});
+		if (fp) {
+			if (fp.tag === tag) {
+				score += 1;
+			}
+			score += 3 * similarity(fp.text, (el.innerText || el.value || "").trim().slice(0, 200));
+			Object.keys(fp.attrs || {}).forEach(function (k) {
+				if (el.getAttribute(k) === fp.attrs[k]) {
+					score += k === "class" || k === "style" ? 0.5 : 1;
+				}
+			});
+			var rect = el.getBoundingClientRect();
+			var d = Math.abs(rect.left + window.scrollX - fp.x) + Math.abs(rect.top + window.scrollY - fp.y);
+			score += 1 / (1 + d / 100);
+		}
+		if (score >= 1.5) {
+			scored.push({ el: el, score: score });
+		}
+	}
+	scored.sort(function (a, b) { return b.score - a.score; });
+	var res = [];
+	for (var k = 0; k < scored.length && res.length < n; k++) {
+		var s = selectorOf(scored[k].el);
+		if (s && res.indexOf(s) < 0) {
+			res.push(s);
+		}
+	}
+	return res;
+})(%s, %s, %d)`
+
+// suggestSelectors is an action that finds at most n selectors similar to the selector.
+func (c *Puppet) suggestSelectors(sel string, n int, res *[]string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		c.mu.Lock()
+		fp := c.fingerprints[sel]
+		c.mu.Unlock()
+		data, err := json.Marshal(sel)
+		if err != nil {
+			return err
+		}
+		fpData, err := json.Marshal(fp)
+		if err != nil {
+			return err
+		}
+		return chromedp.Evaluate(fmt.Sprintf(suggestSelectors, data, fpData, n), res).
+			Do(ctx, h)
+	})
+}
+
+// withSuggestions appends the selectors similar to the selector to the error.
+func (c *Puppet) withSuggestions(ctx context.Context, h cdp.Executor, sel string, err error) error {
+	var suggestions []string
+	if c.suggestSelectors(sel, 3, &suggestions).Do(ctx, h) != nil || len(suggestions) == 0 {
+		return err
+	}
+	return fmt.Errorf("%w, did you mean %s", err, strings.Join(suggestions, " or "))
+}
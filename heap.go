@@ -0,0 +1,57 @@
+package puppet
+
+import (
+	"context"
+	"io"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/heapprofiler"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// HeapSnapshot takes a snapshot of the JavaScript heap of the current target and writes it to w,
+// in the .heapsnapshot format the Memory panel of the DevTools loads.
+func (c *Puppet) HeapSnapshot(w io.Writer) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		s, err := c.sessionOf(h)
+		if err != nil {
+			return err
+		}
+		// the chunks are fired by the session taking the snapshot, before its result
+		var errWrite error
+		l, err := s.add(ctx, func(ev interface{}) {
+			chunk, ok := ev.(*heapprofiler.EventAddHeapSnapshotChunk)
+			if ok && errWrite == nil {
+				_, errWrite = io.WriteString(w, chunk.Chunk)
+			}
+		}, cdproto.EventHeapProfilerAddHeapSnapshotChunk)
+		if err != nil {
+			return err
+		}
+
+		err = heapprofiler.TakeHeapSnapshot().
+			WithReportProgress(false).
+			Do(ctx, s)
+		s.remove(l)
+		l.wait()
+		if err != nil {
+			return err
+		}
+		return errWrite
+	}))
+}
+
+// JSHeapSize returns the used and the total size of the JavaScript heap of the current target in bytes.
+func (c *Puppet) JSHeapSize() (used, total float64, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		used, total, err = runtime.GetHeapUsage().
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return 0, 0, err
+	}
+	return used, total, nil
+}
@@ -0,0 +1,140 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// PushState pushes a new entry onto the session history of the current frame
+// and dispatches a popstate event, so client-side routers pick up the change.
+func (c *Puppet) PushState(url string, state interface{}) (err error) {
+	return c.changeState("pushState", url, state)
+}
+
+// ReplaceState replaces the current entry of the session history of the current frame
+// and dispatches a popstate event, so client-side routers pick up the change.
+func (c *Puppet) ReplaceState(url string, state interface{}) (err error) {
+	return c.changeState("replaceState", url, state)
+}
+
+// WaitPopState waits until the current frame receives a popstate event and returns the new location.
+func (c *Puppet) WaitPopState(timeout time.Duration) (url string, err error) {
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+	expr := fmt.Sprintf(`new Promise(function (resolve, reject) {
+	var timer = setTimeout(function () { reject(new Error("popstate timeout")) }, %d);
+	window.addEventListener("popstate", function () {
+		clearTimeout(timer);
+		resolve(location.href);
+	}, { once: true });
+})`, timeout/time.Millisecond)
+	return url, c.run(ctx,
+		chromedp.Evaluate(expr, &url, awaitPromise))
+}
+
+// WaitForURL waits until the location of the current frame matches the regular expression pattern.
+// The location is polled, so changes made through the history API are observed as well as real navigations.
+func (c *Puppet) WaitForURL(pattern string, timeout time.Duration) (url string, err error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(c.ctx, timeout)
+	defer cancel()
+	for {
+		// the location may not be readable in the middle of a navigation, so just poll again,
+		// without the retries, logs and failure artifacts of run
+		err = c.runOnce(ctx,
+			chromedp.Location(&url))
+		if err == nil && re.MatchString(url) {
+			return url, nil
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = fmt.Errorf("wait for url %q: last url %q: %v", pattern, url, ctx.Err())
+			}
+			return "", err
+		case <-time.After(time.Second / 10):
+		}
+	}
+}
+
+// HistoryEntry is an entry of the navigation history.
+type HistoryEntry struct {
+	ID    int64
+	URL   string
+	Title string
+}
+
+// History returns the navigation history of the current target and the index of the current entry.
+func (c *Puppet) History() (current int, entries []HistoryEntry, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		index, list, err := page.GetNavigationHistory().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		current = int(index)
+		for _, entry := range list {
+			entries = append(entries, HistoryEntry{
+				ID:    entry.ID,
+				URL:   entry.URL,
+				Title: entry.Title,
+			})
+		}
+		return nil
+	}))
+	return current, entries, err
+}
+
+// NavigateToHistoryEntry navigates the current target to the entry of the navigation history at the index.
+func (c *Puppet) NavigateToHistoryEntry(index int) (err error) {
+	return c.run(c.ctx, chromedp.Tasks{
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			_, entries, err := page.GetNavigationHistory().
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			if index < 0 || index >= len(entries) {
+				return fmt.Errorf("history entry %d out of range [0, %d)", index, len(entries))
+			}
+			return page.NavigateToHistoryEntry(entries[index].ID).
+				Do(ctx, h)
+		}),
+		waitComplete,
+	})
+}
+
+func (c *Puppet) changeState(method string, url string, state interface{}) (err error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	u, err := json.Marshal(url)
+	if err != nil {
+		return err
+	}
+	expr := fmt.Sprintf(`(function (state, url) {
+	history.%s(state, "", url);
+	window.dispatchEvent(new PopStateEvent("popstate", { state: state }));
+	return true;
+})(%s, %s)`, method, data, u)
+	var ok bool
+	return c.run(c.ctx,
+		chromedp.Evaluate(expr, &ok))
+}
+
+// awaitPromise makes the evaluation wait for the returned promise to be resolved.
+func awaitPromise(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
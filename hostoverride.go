@@ -0,0 +1,96 @@
+package puppet
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/cdproto/network"
+)
+
+// HostOverride sends the requests of the current target to the host to the address instead,
+// e.g. HostOverride("example.com", "127.0.0.1:8080") points a production hostname at a test backend.
+// The requests are issued by the Puppet with the original url, so TLS certificates are verified against the host.
+func (c *Puppet) HostOverride(host string, addr string) (err error) {
+	host = strings.ToLower(host)
+	dialer := &net.Dialer{}
+	transport := &http.Transport{
+		Proxy: nil,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			h, port, err := net.SplitHostPort(address)
+			if err == nil && strings.ToLower(h) == host {
+				address = addr
+				if _, _, err := net.SplitHostPort(addr); err != nil {
+					address = net.JoinHostPort(addr, port)
+				}
+			}
+			return dialer.DialContext(ctx, network, address)
+		},
+		TLSClientConfig: &tls.Config{},
+	}
+	cli := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// let the browser follow the redirects
+			return http.ErrUseLastResponse
+		},
+	}
+
+	return c.interceptOverride(nil, func(r *Request) {
+		u, err := url.Parse(r.URL)
+		if err != nil || strings.ToLower(u.Hostname()) != host {
+			return
+		}
+		status, header, body, err := r.send(cli)
+		if err != nil {
+			r.Fail()
+			return
+		}
+		r.Fulfill(status, header, body)
+	})
+}
+
+// send sends the request with the client, including the cookies of the browser for the url.
+func (r *Request) send(cli *http.Client) (status int, header http.Header, body []byte, err error) {
+	err = r.LoadPostData()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req, err := http.NewRequest(r.Method, r.URL, strings.NewReader(r.PostData))
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	req = req.WithContext(r.ctx)
+	for k, vs := range r.Header {
+		req.Header[k] = vs
+	}
+	// let the transport negotiate and decode the compression
+	req.Header.Del("Accept-Encoding")
+	cookies, err := network.GetCookies().
+		WithUrls([]string{r.URL}).
+		Do(r.ctx, r.h)
+	if err == nil {
+		for _, cookie := range cookies {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+
+	resp, err := cli.Do(req)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	// the body is already decoded and its length may have changed
+	header = resp.Header
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return resp.StatusCode, header, body, nil
+}
@@ -0,0 +1,152 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/indexeddb"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// IndexedDBKeyRange is a range of keys of an object store, the bounds are numbers or strings,
+// a nil bound leaves the range unbounded.
+type IndexedDBKeyRange struct {
+	Lower     interface{}
+	Upper     interface{}
+	LowerOpen bool
+	UpperOpen bool
+}
+
+// IndexedDBEntry is an entry of an object store.
+type IndexedDBEntry struct {
+	Key        json.RawMessage
+	PrimaryKey json.RawMessage
+	Value      json.RawMessage
+}
+
+// IndexedDBDatabases retrieves the names of the IndexedDB databases of the origin.
+func (c *Puppet) IndexedDBDatabases(origin string) (names []string, err error) {
+	err = c.run(c.ctx, chromedp.Tasks{
+		indexeddb.Enable(),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			names, err = indexeddb.RequestDatabaseNames(origin).
+				Do(ctx, h)
+			return err
+		}),
+	})
+	return names, err
+}
+
+// ReadIndexedDB retrieves all entries of the object store of the IndexedDB database of the origin within the key range,
+// a nil key range retrieves all entries.
+func (c *Puppet) ReadIndexedDB(origin, db, store string, keyRange *IndexedDBKeyRange) (entries []IndexedDBEntry, err error) {
+	var kr *indexeddb.KeyRange
+	if keyRange != nil {
+		kr = &indexeddb.KeyRange{
+			LowerOpen: keyRange.LowerOpen,
+			UpperOpen: keyRange.UpperOpen,
+		}
+		kr.Lower, err = indexedDBKey(keyRange.Lower)
+		if err != nil {
+			return nil, err
+		}
+		kr.Upper, err = indexedDBKey(keyRange.Upper)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	const pageSize = 100
+	err = c.run(c.ctx, chromedp.Tasks{
+		indexeddb.Enable(),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			for skip := int64(0); ; skip += pageSize {
+				req := indexeddb.RequestData(origin, db, store, "", skip, pageSize)
+				if kr != nil {
+					req = req.WithKeyRange(kr)
+				}
+				list, hasMore, err := req.Do(ctx, h)
+				if err != nil {
+					return err
+				}
+				for _, e := range list {
+					entry := IndexedDBEntry{}
+					entry.Key, err = remoteValue(ctx, h, e.Key)
+					if err != nil {
+						return err
+					}
+					entry.PrimaryKey, err = remoteValue(ctx, h, e.PrimaryKey)
+					if err != nil {
+						return err
+					}
+					entry.Value, err = remoteValue(ctx, h, e.Value)
+					if err != nil {
+						return err
+					}
+					entries = append(entries, entry)
+				}
+				if !hasMore {
+					return nil
+				}
+			}
+		}),
+	})
+	return entries, err
+}
+
+// ClearIndexedDB deletes all IndexedDB databases of the origin.
+func (c *Puppet) ClearIndexedDB(origin string) (err error) {
+	names, err := c.IndexedDBDatabases(origin)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		err = c.run(c.ctx,
+			indexeddb.DeleteDatabase(origin, name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexedDBKey(v interface{}) (*indexeddb.Key, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeString, String: v}, nil
+	case int:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeNumber, Number: float64(v)}, nil
+	case int64:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeNumber, Number: float64(v)}, nil
+	case float64:
+		return &indexeddb.Key{Type: indexeddb.KeyTypeNumber, Number: v}, nil
+	}
+	return nil, fmt.Errorf("unsupported IndexedDB key %T", v)
+}
+
+// remoteValue returns the JSON value of the remote object, serializing objects held by reference.
+func remoteValue(ctx context.Context, h cdp.Executor, obj *runtime.RemoteObject) (json.RawMessage, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	if obj.ObjectID == "" {
+		return json.RawMessage(obj.Value), nil
+	}
+	defer runtime.ReleaseObject(obj.ObjectID).Do(ctx, h)
+	v, exp, err := runtime.CallFunctionOn(`function () { return this }`).
+		WithObjectID(obj.ObjectID).
+		WithReturnByValue(true).
+		Do(ctx, h)
+	if err != nil {
+		return nil, err
+	}
+	if exp != nil {
+		return nil, exp
+	}
+	return json.RawMessage(v.Value), nil
+}
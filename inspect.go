@@ -0,0 +1,144 @@
+package puppet
+
+import (
+	"sort"
+	"time"
+)
+
+// Labels are the metadata attached to a browser, a browser context or a target, e.g. a job id.
+type Labels map[string]string
+
+// Topology is the state of the browser managed by the Puppet.
+type Topology struct {
+	Labels   Labels
+	Contexts []ContextTopology
+}
+
+// ContextTopology is the state of a browser context, the default browser context has an empty id.
+type ContextTopology struct {
+	ID      string
+	Labels  Labels
+	Targets []TargetTopology
+}
+
+// TargetTopology is the state of a page target.
+type TargetTopology struct {
+	TargetInfo
+	Labels Labels
+	// Age is the time since the target was created by the Puppet or first seen by it.
+	Age time.Duration
+}
+
+// SetLabel attaches the label to the browser.
+func (c *Puppet) SetLabel(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = setLabel(c.labels, "", key, value)
+}
+
+// SetContextLabel attaches the label to the browser context with the specified id.
+func (c *Puppet) SetContextLabel(id string, key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = setLabel(c.labels, "context/"+id, key, value)
+}
+
+// SetTargetLabel attaches the label to the target with the specified id.
+func (c *Puppet) SetTargetLabel(id string, key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.labels = setLabel(c.labels, "target/"+id, key, value)
+}
+
+func setLabel(labels map[string]Labels, scope string, key, value string) map[string]Labels {
+	if labels == nil {
+		labels = map[string]Labels{}
+	}
+	if labels[scope] == nil {
+		labels[scope] = Labels{}
+	}
+	labels[scope][key] = value
+	return labels
+}
+
+// Inspect returns the topology of the browser, its browser contexts and their page targets with their labels.
+func (c *Puppet) Inspect() (topo *Topology, err error) {
+	targets, err := c.Targets()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.targetSeen == nil {
+		c.targetSeen = map[string]time.Time{}
+	}
+	topo = &Topology{
+		Labels: copyLabels(c.labels[""]),
+	}
+	contexts := map[string]*ContextTopology{}
+	for id := range c.contexts {
+		contexts[id] = &ContextTopology{ID: id}
+	}
+	alive := map[string]bool{}
+	for _, t := range targets {
+		if t.Type != "page" {
+			continue
+		}
+		alive[t.ID] = true
+		seen, ok := c.targetSeen[t.ID]
+		if !ok {
+			seen = now
+			c.targetSeen[t.ID] = now
+		}
+		ctx := contexts[t.ContextID]
+		if ctx == nil {
+			ctx = &ContextTopology{ID: t.ContextID}
+			contexts[t.ContextID] = ctx
+		}
+		ctx.Targets = append(ctx.Targets, TargetTopology{
+			TargetInfo: t,
+			Labels:     copyLabels(c.labels["target/"+t.ID]),
+			Age:        now.Sub(seen),
+		})
+	}
+	// forget the targets which are gone
+	for id := range c.targetSeen {
+		if !alive[id] {
+			delete(c.targetSeen, id)
+			delete(c.labels, "target/"+id)
+		}
+	}
+
+	for id, ctx := range contexts {
+		ctx.Labels = copyLabels(c.labels["context/"+id])
+		topo.Contexts = append(topo.Contexts, *ctx)
+	}
+	sort.Slice(topo.Contexts, func(i, j int) bool {
+		return topo.Contexts[i].ID < topo.Contexts[j].ID
+	})
+	return topo, nil
+}
+
+// seeTarget records the creation of the target with the specified id.
+func (c *Puppet) seeTarget(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.targetSeen == nil {
+		c.targetSeen = map[string]time.Time{}
+	}
+	c.targetSeen[id] = time.Now()
+}
+
+func copyLabels(labels Labels) Labels {
+	if labels == nil {
+		return nil
+	}
+	res := make(Labels, len(labels))
+	for k, v := range labels {
+		res[k] = v
+	}
+	return res
+}
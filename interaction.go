@@ -0,0 +1,159 @@
+package puppet
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// InteractionMetrics are the rendering and responsiveness metrics of an interaction.
+type InteractionMetrics struct {
+	Duration time.Duration
+	// Frames is the number of frames rendered, and DroppedFrames the number of frames
+	// missed by the frames taking longer than a frame interval.
+	Frames        int
+	DroppedFrames int
+	// FPS is the average frame rate.
+	FPS float64
+	// LongestFrame is the longest interval between two frames.
+	LongestFrame time.Duration
+	// LongTasks is the number of tasks blocking the main thread for more than 50ms,
+	// and BlockingTime the sum of their durations beyond 50ms.
+	LongTasks    int
+	BlockingTime time.Duration
+	// InteractionLatency is the longest latency from an input to the next paint, as for INP,
+	// zero if there was no input.
+	InteractionLatency time.Duration
+	// Interactions is the number of inputs observed.
+	Interactions int
+}
+
+// frameInterval is the interval of a frame at 60 frames per second.
+const frameInterval = time.Second / 60
+
+const interactionStart = `(function () {
+	var m = window.__puppetInteraction = {frames: [], longTasks: [], events: [], observers: [], running: true};
+	var observe = function (options, fn) {
+		try {
+			var o = new PerformanceObserver(function (list) { list.getEntries().forEach(fn) });
+			o.observe(options);
+			m.observers.push({observer: o, fn: fn});
+		} catch (e) {}
+	};
+	observe({type: "longtask"}, function (e) { m.longTasks.push(e.duration) });
+	observe({type: "event", durationThreshold: 16}, function (e) {
+		if (e.interactionId) {
+			m.events.push({id: e.interactionId, duration: e.duration});
+		}
+	});
+	m.start = performance.now();
+	var tick = function (t) {
+		if (!m.running) {
+			return;
+		}
+		m.frames.push(t);
+		requestAnimationFrame(tick);
+	};
+	requestAnimationFrame(tick);
+	return true;
+})()`
+
+// interactionStop waits for two frames so the last input is painted, and returns the collected measures.
+const interactionStop = `new Promise(function (resolve) {
+	requestAnimationFrame(function () { requestAnimationFrame(resolve) });
+}).then(function () {
+	var m = window.__puppetInteraction;
+	if (!m) {
+		return null;
+	}
+	m.running = false;
+	m.observers.forEach(function (o) {
+		o.observer.takeRecords().forEach(o.fn);
+		o.observer.disconnect();
+	});
+	delete window.__puppetInteraction;
+	return JSON.stringify({
+		duration: performance.now() - m.start,
+		frames: m.frames,
+		longTasks: m.longTasks,
+		events: m.events
+	});
+})`
+
+// MeasureInteraction measures the frame rate, the long tasks and the interaction latency of the current target
+// while fn runs, e.g. to detect jank regressions of an animation or a click. The measures are lost
+// if fn navigates to another document.
+func (c *Puppet) MeasureInteraction(fn func() error) (metrics *InteractionMetrics, err error) {
+	var ok bool
+	err = c.run(c.ctx,
+		chromedp.Evaluate(interactionStart, &ok))
+	if err != nil {
+		return nil, err
+	}
+	fnErr := fn()
+
+	var res string
+	err = c.run(c.ctx,
+		chromedp.Evaluate(interactionStop, &res, awaitPromise))
+	if fnErr != nil {
+		return nil, fnErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if res == "" {
+		return nil, errors.New("interaction measures lost by a navigation")
+	}
+	var v struct {
+		Duration  float64   `json:"duration"`
+		Frames    []float64 `json:"frames"`
+		LongTasks []float64 `json:"longTasks"`
+		Events    []struct {
+			ID       int     `json:"id"`
+			Duration float64 `json:"duration"`
+		} `json:"events"`
+	}
+	err = json.Unmarshal([]byte(res), &v)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics = &InteractionMetrics{
+		Duration: ms(v.Duration),
+		Frames:   len(v.Frames),
+	}
+	for i := 1; i < len(v.Frames); i++ {
+		d := ms(v.Frames[i] - v.Frames[i-1])
+		if d > metrics.LongestFrame {
+			metrics.LongestFrame = d
+		}
+		if missed := int((d+frameInterval/2)/frameInterval) - 1; missed > 0 {
+			metrics.DroppedFrames += missed
+		}
+	}
+	if metrics.Duration > 0 {
+		metrics.FPS = float64(metrics.Frames) / metrics.Duration.Seconds()
+	}
+	for _, d := range v.LongTasks {
+		metrics.LongTasks++
+		if d > 50 {
+			metrics.BlockingTime += ms(d - 50)
+		}
+	}
+	interactions := map[int]bool{}
+	for _, e := range v.Events {
+		interactions[e.ID] = true
+		if d := ms(e.Duration); d > metrics.InteractionLatency {
+			metrics.InteractionLatency = d
+		}
+	}
+	metrics.Interactions = len(interactions)
+	return metrics, nil
+}
+
+// ms converts the milliseconds to a duration.
+func ms(v float64) time.Duration {
+	return time.Duration(v * float64(time.Millisecond))
+}
@@ -0,0 +1,382 @@
+package puppet
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/mailru/easyjson"
+)
+
+// ResourceType is the type of a resource as the rendering engine perceives it.
+type ResourceType = network.ResourceType
+
+// Resource types.
+const (
+	ResourceDocument   = network.ResourceTypeDocument
+	ResourceStylesheet = network.ResourceTypeStylesheet
+	ResourceImage      = network.ResourceTypeImage
+	ResourceMedia      = network.ResourceTypeMedia
+	ResourceFont       = network.ResourceTypeFont
+	ResourceScript     = network.ResourceTypeScript
+	ResourceXHR        = network.ResourceTypeXHR
+	ResourceFetch      = network.ResourceTypeFetch
+	ResourceWebSocket  = network.ResourceTypeWebSocket
+	ResourceOther      = network.ResourceTypeOther
+)
+
+// Request is a request paused by the interception, an interceptor can modify, fail or fulfill it.
+// In the response stage the response has been received but not yet passed to the page.
+type Request struct {
+	URL    string
+	Method string
+	Header http.Header
+	// PostData is the body of the request, it may be empty for large bodies, see LoadPostData.
+	PostData     string
+	ResourceType ResourceType
+	FrameID      string
+	// IsNavigation reports whether the request loads a document into a frame.
+	IsNavigation bool
+
+	// StatusCode is the status code of the response, zero in the request stage.
+	StatusCode int
+	// ResponseHeader is the header of the response, nil in the request stage.
+	ResponseHeader http.Header
+
+	ctx       context.Context
+	h         cdp.Executor
+	id        fetch.RequestID
+	networkID network.RequestID
+	// postData is the original body, and hasPostData reports whether the request has a body
+	postData    string
+	hasPostData bool
+
+	modified  bool
+	failed    bool
+	fulfilled bool
+	status    int
+	header    http.Header
+	body      []byte
+	// resolved are called once the request is failed or fulfilled by an interceptor
+	resolved []func()
+}
+
+// Response reports whether the request is paused in the response stage.
+func (r *Request) Response() bool {
+	return r.ResponseHeader != nil
+}
+
+// Continue marks the URL, method, header and post data of the request as modified,
+// so they are sent instead of the original ones.
+func (r *Request) Continue() {
+	r.modified = true
+}
+
+// Fail fails the request as blocked by the client.
+func (r *Request) Fail() {
+	r.failed = true
+}
+
+// Fulfill fulfills the request with the response, without sending it to the network.
+func (r *Request) Fulfill(status int, header http.Header, body []byte) {
+	r.fulfilled = true
+	r.status = status
+	r.header = header
+	r.body = body
+}
+
+// Body retrieves the body of the response, only available in the response stage.
+func (r *Request) Body() ([]byte, error) {
+	return fetch.GetResponseBody(r.id).
+		Do(r.ctx, r.h)
+}
+
+// LoadPostData loads the body of the request into PostData if it was left out by the browser,
+// e.g. for a large upload, only available in the request stage.
+func (r *Request) LoadPostData() (err error) {
+	if !r.hasPostData || r.PostData != "" || r.postData != "" {
+		return nil
+	}
+	data, err := network.GetRequestPostData(r.networkID).
+		Do(r.ctx, r.h)
+	if err != nil {
+		return err
+	}
+	r.PostData, r.postData = data, data
+	return nil
+}
+
+func (r *Request) done() bool {
+	return r.failed || r.fulfilled
+}
+
+// onResolved calls fn once the request is failed or fulfilled by an interceptor, without reaching the network.
+func (r *Request) onResolved(fn func()) {
+	r.resolved = append(r.resolved, fn)
+}
+
+// interceptor handles the paused requests matched by its pattern.
+type interceptor struct {
+	pattern  *regexp.Regexp
+	response bool
+	fn       func(r *Request)
+	// override reports whether the interceptor is an override removed by ResetOverrides.
+	override bool
+}
+
+// intercept adds the interceptor for the requests of the current target whose url matches the pattern,
+// nil matches all. Interceptors are called in the order they are added until a request is failed or fulfilled.
+func (c *Puppet) intercept(pattern *regexp.Regexp, response bool, fn func(r *Request)) (err error) {
+	return c.addInterceptor(&interceptor{
+		pattern:  pattern,
+		response: response,
+		fn:       fn,
+	})
+}
+
+// interceptOverride adds the interceptor as intercept does, as an override removed by ResetOverrides.
+func (c *Puppet) interceptOverride(pattern *regexp.Regexp, fn func(r *Request)) (err error) {
+	return c.addInterceptor(&interceptor{
+		pattern:  pattern,
+		fn:       fn,
+		override: true,
+	})
+}
+
+func (c *Puppet) addInterceptor(i *interceptor) (err error) {
+	c.mu.Lock()
+	c.interceptors = append(c.interceptors, i)
+	start := !c.intercepting
+	c.intercepting = true
+	enableResponse := i.response && !c.interceptResponse
+	if i.response {
+		c.interceptResponse = true
+	}
+	c.mu.Unlock()
+
+	if start {
+		err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+			paused, ok := ev.(*requestPaused)
+			if !ok {
+				return
+			}
+			// interceptors may block, e.g. to throttle requests
+			go c.handlePaused(ctx, h, paused)
+		}, cdproto.EventFetchRequestPaused)
+		if err != nil {
+			return err
+		}
+	}
+	if start || enableResponse {
+		// the requests are paused on the session enabling the interception, and continued on it
+		return c.runSession(
+			fetch.Enable().WithPatterns(c.interceptPatterns()))
+	}
+	return nil
+}
+
+func (c *Puppet) interceptPatterns() []*fetch.RequestPattern {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	patterns := []*fetch.RequestPattern{
+		{URLPattern: "*", RequestStage: fetch.RequestStageRequest},
+	}
+	if c.interceptResponse {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", RequestStage: fetch.RequestStageResponse})
+	}
+	return patterns
+}
+
+// requestPaused is the Fetch.requestPaused event, with the fields cdproto lacks.
+type requestPaused struct {
+	fetch.EventRequestPaused
+	// NetworkID is the id of the request in the Network domain.
+	NetworkID network.RequestID
+	Body      requestBody
+}
+
+func (e *requestPaused) UnmarshalJSON(data []byte) error {
+	err := easyjson.Unmarshal(data, &e.EventRequestPaused)
+	if err != nil {
+		return err
+	}
+	var extra struct {
+		NetworkID network.RequestID `json:"networkId"`
+		Request   requestBody       `json:"request"`
+	}
+	err = json.Unmarshal(data, &extra)
+	if err != nil {
+		return err
+	}
+	e.NetworkID = extra.NetworkID
+	e.Body = extra.Request
+	return nil
+}
+
+// requestBody is the body of a request as entries, which cdproto lacks.
+type requestBody struct {
+	PostDataEntries []struct {
+		Bytes string `json:"bytes"`
+	} `json:"postDataEntries"`
+}
+
+func (c *Puppet) handlePaused(ctx context.Context, h cdp.Executor, ev *requestPaused) {
+	r := &Request{
+		URL:          ev.Request.URL + ev.Request.URLFragment,
+		Method:       ev.Request.Method,
+		Header:       http.Header{},
+		PostData:     requestPostData(ev.Request, ev.Body),
+		ResourceType: ev.ResourceType,
+		FrameID:      string(ev.FrameID),
+		IsNavigation: ev.ResourceType == network.ResourceTypeDocument,
+		ctx:          ctx,
+		h:            h,
+		id:           ev.RequestID,
+		networkID:    ev.NetworkID,
+		hasPostData:  ev.Request.HasPostData,
+	}
+	r.postData = r.PostData
+	for k, v := range ev.Request.Headers {
+		if s, ok := v.(string); ok {
+			r.Header.Set(k, s)
+		}
+	}
+	response := ev.ResponseStatusCode != 0 || ev.ResponseErrorReason != ""
+	if response {
+		r.StatusCode = int(ev.ResponseStatusCode)
+		r.ResponseHeader = http.Header{}
+		for _, e := range ev.ResponseHeaders {
+			r.ResponseHeader.Add(e.Name, e.Value)
+		}
+	}
+
+	c.mu.Lock()
+	interceptors := append([]*interceptor(nil), c.interceptors...)
+	c.mu.Unlock()
+	for _, i := range interceptors {
+		if i.response != response || (i.pattern != nil && !i.pattern.MatchString(r.URL)) {
+			continue
+		}
+		i.fn(r)
+		if r.done() {
+			break
+		}
+	}
+
+	var err error
+	switch {
+	case r.failed:
+		err = fetch.FailRequest(r.id, network.ErrorReasonBlockedByClient).
+			Do(ctx, h)
+	case r.fulfilled:
+		err = fetch.FulfillRequest(r.id, int64(r.status), headerEntries(r.header)).
+			WithBody(base64.StdEncoding.EncodeToString(r.body)).
+			Do(ctx, h)
+	case r.modified && !response:
+		p := fetch.ContinueRequest(r.id).
+			WithURL(r.URL).
+			WithMethod(r.Method).
+			WithHeaders(headerEntries(r.Header))
+		// the body is sent only if changed, as it may be incomplete
+		if r.PostData != r.postData {
+			p = p.WithPostData(base64.StdEncoding.EncodeToString([]byte(r.PostData)))
+		}
+		err = p.Do(ctx, h)
+	default:
+		err = fetch.ContinueRequest(r.id).
+			Do(ctx, h)
+	}
+	if err != nil {
+		// the request may have been canceled by the page in the meantime
+		fetch.ContinueRequest(r.id).Do(ctx, h)
+	}
+	if r.done() {
+		for _, fn := range r.resolved {
+			fn()
+		}
+	}
+}
+
+// requestPostData returns the body of the request, decoded from the entries if any
+// so binary bodies are not garbled.
+func requestPostData(req *network.Request, body requestBody) string {
+	if len(body.PostDataEntries) == 0 {
+		return req.PostData
+	}
+	var sb strings.Builder
+	for _, e := range body.PostDataEntries {
+		data, err := base64.StdEncoding.DecodeString(e.Bytes)
+		if err != nil {
+			return req.PostData
+		}
+		sb.Write(data)
+	}
+	return sb.String()
+}
+
+func headerEntries(header http.Header) []*fetch.HeaderEntry {
+	entries := make([]*fetch.HeaderEntry, 0, len(header))
+	for k, vs := range header {
+		for _, v := range vs {
+			entries = append(entries, &fetch.HeaderEntry{
+				Name:  k,
+				Value: v,
+			})
+		}
+	}
+	return entries
+}
+
+var globCache sync.Map
+
+// globRegexp converts the glob pattern, where * matches any sequence of characters, to a regular expression.
+func globRegexp(pattern string) *regexp.Regexp {
+	if re, ok := globCache.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	re := regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+	globCache.Store(pattern, re)
+	return re
+}
+
+// BlockResourceTypes fails all requests of the current target loading resources of the types.
+func (c *Puppet) BlockResourceTypes(types ...ResourceType) (err error) {
+	blocked := map[ResourceType]bool{}
+	for _, t := range types {
+		blocked[t] = true
+	}
+	return c.intercept(nil, false, func(r *Request) {
+		if blocked[r.ResourceType] {
+			r.Fail()
+		}
+	})
+}
+
+// BlockURLs fails all requests of the current target whose url matches any of the glob patterns,
+// where * matches any sequence of characters, e.g. "*.doubleclick.net/*".
+func (c *Puppet) BlockURLs(patterns ...string) (err error) {
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		res = append(res, globRegexp(pattern))
+	}
+	return c.intercept(nil, false, func(r *Request) {
+		for _, re := range res {
+			if re.MatchString(r.URL) {
+				r.Fail()
+				return
+			}
+		}
+	})
+}
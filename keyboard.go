@@ -0,0 +1,152 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// keyPress is an action that presses and releases the key with the virtual key code, text is typed if not empty.
+func keyPress(key string, code int64, text string, modifiers input.Modifier) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		down := input.DispatchKeyEvent(input.KeyRawDown)
+		if text != "" {
+			down = input.DispatchKeyEvent(input.KeyDown).
+				WithText(text).
+				WithUnmodifiedText(text)
+		}
+		err := down.
+			WithKey(key).
+			WithCode(key).
+			WithWindowsVirtualKeyCode(code).
+			WithNativeVirtualKeyCode(code).
+			WithModifiers(modifiers).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		return input.DispatchKeyEvent(input.KeyUp).
+			WithKey(key).
+			WithCode(key).
+			WithWindowsVirtualKeyCode(code).
+			WithNativeVirtualKeyCode(code).
+			WithModifiers(modifiers).
+			Do(ctx, h)
+	})
+}
+
+// PressTab moves the focus to the next focusable element, as the Tab key does.
+func (c *Puppet) PressTab() (err error) {
+	return c.run(c.ctx,
+		keyPress("Tab", 9, "", 0))
+}
+
+// PressShiftTab moves the focus to the previous focusable element, as Shift+Tab does.
+func (c *Puppet) PressShiftTab() (err error) {
+	return c.run(c.ctx,
+		keyPress("Tab", 9, "", input.ModifierShift))
+}
+
+// PressEnter presses the Enter key on the focused element, e.g. to follow a link or press a button.
+func (c *Puppet) PressEnter() (err error) {
+	return c.run(c.ctx,
+		keyPress("Enter", 13, "\r", 0))
+}
+
+// ElementInfo describes an element of a page.
+type ElementInfo struct {
+	Tag  string `json:"tag"`
+	Role string `json:"role"`
+	Name string `json:"name"`
+	// Selector is a CSS selector of the element.
+	Selector string `json:"selector"`
+}
+
+// FocusStop is an element reached by the keyboard.
+type FocusStop struct {
+	ElementInfo
+	// FocusVisible reports whether the focus of the element is visibly indicated,
+	// by an outline, a box shadow or a change of the border or the background.
+	FocusVisible bool `json:"focusVisible"`
+}
+
+// describeElement is the javascript function describing an element as ElementInfo.
+const describeElement = `function (el) {
+	function path(el) {
+		var parts = [];
+		for (; el && el.nodeType === 1 && el !== document.documentElement; el = el.parentElement) {
+			if (el.id) {
+				parts.unshift("#" + CSS.escape(el.id));
+				break;
+			}
+			var index = 1;
+			for (var sib = el.previousElementSibling; sib; sib = sib.previousElementSibling) {
+				if (sib.tagName === el.tagName) {
+					index++;
+				}
+			}
+			parts.unshift(el.tagName.toLowerCase() + ":nth-of-type(" + index + ")");
+		}
+		return parts.join(" > ");
+	}
+	var name = el.getAttribute("aria-label") || (el.labels && el.labels.length ? el.labels[0].innerText : "") || el.innerText || el.value || el.getAttribute("title") || "";
+	return {
+		tag: el.tagName.toLowerCase(),
+		role: el.getAttribute("role") || "",
+		name: String(name).replace(/\s+/g, " ").trim().slice(0, 100),
+		selector: path(el)
+	};
+}`
+
+// focusStop describes the focused element, comparing its styles with the ones it has unfocused.
+const focusStop = `(function () {
+	var el = document.activeElement;
+	if (!el || el === document.body || el === document.documentElement) {
+		return null;
+	}
+	var style = window.getComputedStyle(el);
+	var focused = [style.outlineStyle !== "none" && parseFloat(style.outlineWidth) > 0, style.boxShadow, style.borderColor, style.backgroundColor];
+	el.blur();
+	var plain = window.getComputedStyle(el);
+	var unfocused = [false, plain.boxShadow, plain.borderColor, plain.backgroundColor];
+	el.focus({ preventScroll: true });
+	var stop = (` + describeElement + `)(el);
+	stop.focusVisible = focused[0] || focused[1] !== unfocused[1] || focused[2] !== unfocused[2] || focused[3] !== unfocused[3];
+	return stop;
+})()`
+
+// FocusOrder tabs through the current page from its start, and returns the elements in the order they are focused,
+// until the focus cycles back to the first element, leaves the page, or max elements are reached.
+func (c *Puppet) FocusOrder(max int) (stops []FocusStop, err error) {
+	var ok bool
+	err = c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	if (document.activeElement && document.activeElement.blur) {
+		document.activeElement.blur();
+	}
+	window.focus();
+	return true;
+})()`, &ok))
+	if err != nil {
+		return nil, err
+	}
+	for len(stops) < max {
+		err = c.PressTab()
+		if err != nil {
+			return nil, err
+		}
+		var stop *FocusStop
+		err = c.run(c.ctx,
+			chromedp.Evaluate(focusStop, &stop))
+		if err != nil {
+			return nil, err
+		}
+		if stop == nil || (len(stops) != 0 && stop.Selector == stops[0].Selector) {
+			break
+		}
+		stops = append(stops, *stop)
+	}
+	return stops, nil
+}
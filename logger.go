@@ -0,0 +1,106 @@
+package puppet
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"unicode"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+)
+
+// LogLevel is the severity of a log message.
+type LogLevel int
+
+// Log levels.
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+// String returns the name of the level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Logger receives the structured log messages of a Puppet, the keyvals are alternating keys and values
+// like the ones of slog and zap's SugaredLogger.
+//
+// The messages logged are:
+//   - "action start" with the action, the target, and the selector, url or expression of some actions
+//   - "action end" with the keyvals of "action start", the duration and the error if any
+//   - "navigated" with the target, the frame and the url
+//   - "cdp" with the message, only with WithCDPLogging
+type Logger interface {
+	Log(level LogLevel, msg string, keyvals ...interface{})
+}
+
+// Tracer traces the actions of a Puppet, e.g. as the spans of a distributed trace.
+type Tracer interface {
+	// StartAction is called before every action with the keyvals of "action start" and the context of the action,
+	// it returns the context the action runs with and the function called with the error of the action once it ends.
+	StartAction(ctx context.Context, keyvals ...interface{}) (context.Context, func(err error))
+}
+
+// LoggerFunc is an adapter to use a function as a Logger,
+// e.g. LoggerFunc(func(level LogLevel, msg string, keyvals ...interface{}) { sugar.Infow(msg, keyvals...) }).
+type LoggerFunc func(level LogLevel, msg string, keyvals ...interface{})
+
+// Log calls f.
+func (f LoggerFunc) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	f(level, msg, keyvals...)
+}
+
+// logNavigations logs the navigations of the frames of the active target.
+func (c *Puppet) logNavigations() (err error) {
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		nav, ok := ev.(*page.EventFrameNavigated)
+		if !ok {
+			return
+		}
+		c.logger.Log(LogInfo, "navigated", "target", c.target, "frame", nav.Frame.ID, "url", nav.Frame.URL)
+	}, cdproto.EventPageFrameNavigated)
+}
+
+// actionName returns the name of the outermost exported Puppet method on the stack, e.g. "Click".
+func actionName() string {
+	pc := make([]uintptr, 16)
+	n := runtime.Callers(3, pc)
+	frames := runtime.CallersFrames(pc[:n])
+	name := ""
+	for {
+		frame, more := frames.Next()
+		const recv = ".(*Puppet)."
+		if i := strings.LastIndex(frame.Function, recv); i >= 0 {
+			method := frame.Function[i+len(recv):]
+			if method != "" && unicode.IsUpper(rune(method[0])) {
+				name = method
+			}
+		}
+		if !more {
+			break
+		}
+	}
+	return name
+}
+
+type logAttrsKey struct{}
+
+// logAttrs returns the context of the Puppet carrying the keyvals logged with the actions run with it.
+func (c *Puppet) logAttrs(keyvals ...interface{}) context.Context {
+	return context.WithValue(c.ctx, logAttrsKey{}, keyvals)
+}
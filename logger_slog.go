@@ -0,0 +1,28 @@
+//go:build go1.21
+// +build go1.21
+
+package puppet
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogLogger adapts the slog.Logger to a Logger.
+func SlogLogger(l *slog.Logger) Logger {
+	return LoggerFunc(func(level LogLevel, msg string, keyvals ...interface{}) {
+		l.Log(context.Background(), slogLevel(level), msg, keyvals...)
+	})
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogDebug:
+		return slog.LevelDebug
+	case LogWarn:
+		return slog.LevelWarn
+	case LogError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
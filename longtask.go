@@ -0,0 +1,175 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// LongTask is a task blocking the main thread for more than 50ms, or a long animation frame of several tasks
+// and the rendering, taking more than 50ms, when the browser supports them.
+type LongTask struct {
+	// Start is the start of the task since the navigation of the page.
+	Start    time.Duration
+	Duration time.Duration
+	// Blocking is the time the task blocked the inputs, its duration beyond 50ms for a task,
+	// and the blocking duration reported by the browser for a long animation frame.
+	Blocking time.Duration
+	// Scripts are the scripts run by the task, empty if the browser does not attribute the long tasks.
+	Scripts []LongTaskScript
+}
+
+// LongTaskScript is a script run by a long task.
+type LongTaskScript struct {
+	URL      string
+	Function string
+	// Invoker is what ran the script, e.g. "IMG#logo.onload" or "Window.setTimeout".
+	Invoker  string
+	Duration time.Duration
+}
+
+// LongTaskReport summarizes the long tasks of a page.
+type LongTaskReport struct {
+	Tasks []LongTask
+	// Total is the sum of the durations of the long tasks, and BlockingTime the sum of their blocking times.
+	Total        time.Duration
+	BlockingTime time.Duration
+	// Scripts are the script urls run by the long tasks, the longest first.
+	Scripts []ScriptBlocking
+}
+
+// ScriptBlocking is the time a script url ran in long tasks.
+type ScriptBlocking struct {
+	URL      string
+	Duration time.Duration
+	Tasks    int
+}
+
+const longTaskEntry = `function (e) {
+	return {
+		start: e.startTime,
+		duration: e.duration,
+		blocking: e.entryType === "long-animation-frame" ? e.blockingDuration || 0 : Math.max(e.duration - 50, 0),
+		scripts: (e.scripts || []).map(function (s) {
+			return {url: s.sourceURL || "", function: s.sourceFunctionName || "", invoker: s.invoker || "", duration: s.duration};
+		})
+	};
+}`
+
+// longTaskCollector collects the long tasks from the start of the document, attributed to scripts
+// by the long animation frames if supported.
+const longTaskCollector = `(function () {
+	var tasks = window.__puppetLongTasks = [];
+	var entry = ` + longTaskEntry + `;
+	var observe = function (type) {
+		var o = new PerformanceObserver(function (list) {
+			list.getEntries().forEach(function (e) { tasks.push(entry(e)) });
+		});
+		o.observe({type: type, buffered: true});
+	};
+	try {
+		observe("long-animation-frame");
+	} catch (e) {
+		try { observe("longtask") } catch (e) {}
+	}
+})();`
+
+// longTaskRead returns the long tasks collected, or else the long animation frames buffered by the browser.
+const longTaskRead = `(function () {
+	if (window.__puppetLongTasks) {
+		return JSON.stringify(window.__puppetLongTasks);
+	}
+	var entries = [];
+	try {
+		var o = new PerformanceObserver(function () {});
+		o.observe({type: "long-animation-frame", buffered: true});
+		entries = o.takeRecords();
+		o.disconnect();
+	} catch (e) {}
+	return JSON.stringify(entries.map(` + longTaskEntry + `));
+})()`
+
+// CollectLongTasks starts collecting the long tasks of the pages of the current target from their start,
+// so LongTasks reports all of them. It applies to the pages loaded afterwards.
+func (c *Puppet) CollectLongTasks() (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(longTaskCollector).
+			Do(ctx, h)
+		return err
+	}))
+}
+
+// LongTasks returns the long tasks of the page of the current target with the scripts they ran,
+// e.g. to find the scripts blocking the main thread during the load. Without CollectLongTasks,
+// only the long tasks buffered by the browser are reported, if it supports the long animation frames.
+func (c *Puppet) LongTasks() (report *LongTaskReport, err error) {
+	var res string
+	err = c.run(c.ctx,
+		chromedp.Evaluate(longTaskRead, &res))
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		Start    float64 `json:"start"`
+		Duration float64 `json:"duration"`
+		Blocking float64 `json:"blocking"`
+		Scripts  []struct {
+			URL      string  `json:"url"`
+			Function string  `json:"function"`
+			Invoker  string  `json:"invoker"`
+			Duration float64 `json:"duration"`
+		} `json:"scripts"`
+	}
+	err = json.Unmarshal([]byte(res), &entries)
+	if err != nil {
+		return nil, err
+	}
+
+	report = &LongTaskReport{}
+	scripts := map[string]*ScriptBlocking{}
+	for _, e := range entries {
+		task := LongTask{
+			Start:    ms(e.Start),
+			Duration: ms(e.Duration),
+			Blocking: ms(e.Blocking),
+		}
+		report.Total += task.Duration
+		report.BlockingTime += task.Blocking
+		seen := map[string]bool{}
+		for _, s := range e.Scripts {
+			script := LongTaskScript{
+				URL:      s.URL,
+				Function: s.Function,
+				Invoker:  s.Invoker,
+				Duration: ms(s.Duration),
+			}
+			task.Scripts = append(task.Scripts, script)
+			sb, ok := scripts[s.URL]
+			if !ok {
+				sb = &ScriptBlocking{URL: s.URL}
+				scripts[s.URL] = sb
+			}
+			sb.Duration += script.Duration
+			if !seen[s.URL] {
+				seen[s.URL] = true
+				sb.Tasks++
+			}
+		}
+		report.Tasks = append(report.Tasks, task)
+	}
+	for _, sb := range scripts {
+		report.Scripts = append(report.Scripts, *sb)
+	}
+	sort.Slice(report.Scripts, func(i, j int) bool {
+		if report.Scripts[i].Duration != report.Scripts[j].Duration {
+			return report.Scripts[i].Duration > report.Scripts[j].Duration
+		}
+		return report.Scripts[i].URL < report.Scripts[j].URL
+	})
+	return report, nil
+}
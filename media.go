@@ -0,0 +1,224 @@
+package puppet
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// MediaState is the playback state of a <video> or <audio> element.
+type MediaState struct {
+	Paused       bool
+	Ended        bool
+	Seeking      bool
+	Muted        bool
+	Volume       float64
+	PlaybackRate float64
+	CurrentTime  time.Duration
+	// Duration is the duration of the media, zero if unknown, e.g. for live streams.
+	Duration time.Duration
+	// ReadyState is the readiness of the media, from 0 (HAVE_NOTHING) to 4 (HAVE_ENOUGH_DATA).
+	ReadyState int
+	// Error is the message of the error of the media, if any.
+	Error string
+}
+
+// Playing reports whether the media is playing, not paused, ended nor waiting for data.
+func (s *MediaState) Playing() bool {
+	return !s.Paused && !s.Ended && !s.Seeking && s.ReadyState > 2
+}
+
+const mediaState = `function () {
+	return {
+		paused: this.paused,
+		ended: this.ended,
+		seeking: this.seeking,
+		muted: this.muted,
+		volume: this.volume,
+		playbackRate: this.playbackRate,
+		currentTime: this.currentTime,
+		duration: isFinite(this.duration) ? this.duration : 0,
+		readyState: this.readyState,
+		error: this.error ? (this.error.message || "code " + this.error.code) : ""
+	};
+}`
+
+// mediaPlay plays the media, muting it if the autoplay policy does not allow playing with sound.
+const mediaPlay = `function () {
+	var media = this;
+	return media.play().catch(function (e) {
+		if (e.name !== "NotAllowedError" || media.muted) {
+			throw e;
+		}
+		media.muted = true;
+		return media.play();
+	}).then(function () {});
+}`
+
+const mediaSeek = `function (t) {
+	var media = this;
+	return new Promise(function (resolve) {
+		if (media.currentTime === t && !media.seeking) {
+			resolve();
+			return;
+		}
+		media.addEventListener("seeked", function () { resolve() }, {once: true});
+		media.currentTime = t;
+	}).then(function () {
+		return new Promise(function (resolve) {
+			requestAnimationFrame(function () { requestAnimationFrame(resolve) });
+		});
+	});
+}`
+
+// mediaFrame draws the current frame of the video to a canvas, or returns the rect of the video
+// in the viewport if the video is not same origin.
+const mediaFrame = `function () {
+	try {
+		var canvas = document.createElement("canvas");
+		canvas.width = this.videoWidth;
+		canvas.height = this.videoHeight;
+		canvas.getContext("2d").drawImage(this, 0, 0);
+		return {data: canvas.toDataURL("image/png")};
+	} catch (e) {
+		var r = this.getBoundingClientRect();
+		return {x: r.left + window.scrollX, y: r.top + window.scrollY, width: r.width, height: r.height};
+	}
+}`
+
+// Play plays the <video> or <audio> element, muted if the autoplay policy of the browser requires it.
+func (c *Puppet) Play(sel string) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		callOn(sel, mediaPlay, nil))
+}
+
+// Pause pauses the <video> or <audio> element.
+func (c *Puppet) Pause(sel string) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		callOn(sel, `function () {
+	this.pause();
+}`, nil))
+}
+
+// Seek seeks the <video> or <audio> element to the time, and waits until the frame is presented.
+func (c *Puppet) Seek(sel string, t time.Duration) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		callOn(sel, mediaSeek, nil, t.Seconds()))
+}
+
+// SetPlaybackRate sets the playback rate of the <video> or <audio> element, 1 is the normal speed.
+func (c *Puppet) SetPlaybackRate(sel string, rate float64) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		callOn(sel, `function (rate) {
+	this.playbackRate = rate;
+}`, nil, rate))
+}
+
+// MediaState returns the playback state of the <video> or <audio> element.
+func (c *Puppet) MediaState(sel string) (state *MediaState, err error) {
+	sel = c.selector(sel)
+	var v struct {
+		Paused       bool    `json:"paused"`
+		Ended        bool    `json:"ended"`
+		Seeking      bool    `json:"seeking"`
+		Muted        bool    `json:"muted"`
+		Volume       float64 `json:"volume"`
+		PlaybackRate float64 `json:"playbackRate"`
+		CurrentTime  float64 `json:"currentTime"`
+		Duration     float64 `json:"duration"`
+		ReadyState   int     `json:"readyState"`
+		Error        string  `json:"error"`
+	}
+	err = c.run(c.ctx,
+		callOn(sel, mediaState, &v))
+	if err != nil {
+		return nil, err
+	}
+	state = &MediaState{
+		Paused:       v.Paused,
+		Ended:        v.Ended,
+		Seeking:      v.Seeking,
+		Muted:        v.Muted,
+		Volume:       v.Volume,
+		PlaybackRate: v.PlaybackRate,
+		CurrentTime:  time.Duration(v.CurrentTime * float64(time.Second)),
+		Duration:     time.Duration(v.Duration * float64(time.Second)),
+		ReadyState:   v.ReadyState,
+		Error:        v.Error,
+	}
+	return state, nil
+}
+
+// WaitMediaState polls the playback state of the <video> or <audio> element until fn returns true
+// or the context is done, e.g. to assert that a video starts playing or reaches a time.
+func (c *Puppet) WaitMediaState(ctx context.Context, sel string, fn func(*MediaState) bool) (state *MediaState, err error) {
+	ticker := time.NewTicker(time.Second / 10)
+	defer ticker.Stop()
+	for {
+		state, err = c.MediaState(sel)
+		if err != nil {
+			return nil, err
+		}
+		if fn(state) {
+			return state, nil
+		}
+		select {
+		case <-ctx.Done():
+			return state, fmt.Errorf("media state of %q: %w", sel, ErrTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// CaptureFrame pauses the <video> element, seeks it to the time and captures the frame as a PNG image,
+// at the resolution of the video for same origin videos, or else as displayed in the page.
+func (c *Puppet) CaptureFrame(sel string, at time.Duration) (res []byte, err error) {
+	sel = c.selector(sel)
+	var frame struct {
+		Data   string  `json:"data"`
+		X      float64 `json:"x"`
+		Y      float64 `json:"y"`
+		Width  float64 `json:"width"`
+		Height float64 `json:"height"`
+	}
+	err = c.run(c.ctx, chromedp.Tasks{
+		callOn(sel, `function () {
+	this.pause();
+}`, nil),
+		callOn(sel, mediaSeek, nil, at.Seconds()),
+		callOn(sel, mediaFrame, &frame),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if frame.Data != "" {
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(frame.Data, "data:image/png;base64,"))
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		res, err = page.CaptureScreenshot().
+			WithClip(&page.Viewport{
+				X:      frame.X,
+				Y:      frame.Y,
+				Width:  frame.Width,
+				Height: frame.Height,
+				Scale:  1,
+			}).
+			Do(ctx, h)
+		return err
+	}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
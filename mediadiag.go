@@ -0,0 +1,287 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// MediaSession is the playback of a <video> or <audio> element, with its events and the requests
+// of its manifests and segments.
+type MediaSession struct {
+	ID int
+	// Source is the current source of the element, a blob url for Media Source Extensions players.
+	Source   string
+	Events   []MediaEvent
+	Requests []MediaRequest
+	// Stalls is the number of times the playback waited for data after it started playing.
+	Stalls int
+	// StallTime is the time the playback waited for data after it started playing.
+	StallTime time.Duration
+
+	started    bool
+	stallStart time.Time
+}
+
+// MediaEvent is an event of a media element, e.g. "waiting", "stalled" or "playing".
+type MediaEvent struct {
+	Type        string
+	CurrentTime time.Duration
+	// Buffered is the duration buffered ahead of the current time.
+	Buffered time.Duration
+	Time     time.Time
+}
+
+// MediaRequest is a request of a media manifest or segment.
+type MediaRequest struct {
+	URL string
+	// Kind is "manifest" for HLS and DASH manifests, or else "segment".
+	Kind       string
+	StatusCode int
+	Bytes      int64
+	Start      time.Time
+	Duration   time.Duration
+	// Failed reports whether the request failed, e.g. was aborted by the player.
+	Failed bool
+}
+
+const mediaBinding = "__puppetMediaEvent"
+
+const mediaListener = `(function () {
+	var sessions = 0;
+	["loadstart", "play", "playing", "waiting", "stalled", "pause", "seeking", "seeked", "ended", "error"].forEach(function (type) {
+		document.addEventListener(type, function (e) {
+			var media = e.target;
+			if (!(media instanceof HTMLMediaElement)) {
+				return;
+			}
+			if (!media.__puppetMediaSession || type === "loadstart") {
+				media.__puppetMediaSession = ++sessions;
+			}
+			var buffered = 0;
+			for (var i = 0; i < media.buffered.length; i++) {
+				if (media.buffered.start(i) <= media.currentTime && media.currentTime <= media.buffered.end(i)) {
+					buffered = media.buffered.end(i) - media.currentTime;
+				}
+			}
+			window.` + mediaBinding + `(JSON.stringify({
+				session: media.__puppetMediaSession,
+				type: type,
+				src: media.currentSrc,
+				currentTime: media.currentTime,
+				buffered: buffered
+			}));
+		}, true);
+	});
+})();`
+
+// mediaExts are the extensions of the urls of the media manifests and segments.
+var mediaExts = map[string]string{
+	".m3u8": "manifest",
+	".m3u":  "manifest",
+	".mpd":  "manifest",
+	".ts":   "segment",
+	".m4s":  "segment",
+	".m4v":  "segment",
+	".m4a":  "segment",
+	".mp4":  "segment",
+	".aac":  "segment",
+	".webm": "segment",
+	".cmfv": "segment",
+	".cmfa": "segment",
+	".vtt":  "segment",
+}
+
+// mediaKind returns the kind of the media request, or empty if it is not a media request.
+func mediaKind(rawurl string, typ network.ResourceType, mimeType string) string {
+	mimeType = strings.ToLower(mimeType)
+	switch {
+	case strings.Contains(mimeType, "mpegurl"), strings.Contains(mimeType, "dash+xml"):
+		return "manifest"
+	case strings.HasPrefix(mimeType, "video/"), strings.HasPrefix(mimeType, "audio/"):
+		return "segment"
+	}
+	u := rawurl
+	if i := strings.IndexAny(u, "?#"); i >= 0 {
+		u = u[:i]
+	}
+	if kind, ok := mediaExts[strings.ToLower(path.Ext(u))]; ok {
+		return kind
+	}
+	if typ == network.ResourceTypeMedia {
+		return "segment"
+	}
+	return ""
+}
+
+// CollectMediaDiagnostics starts collecting the playback sessions of the media elements of the current target,
+// with their buffering and stall events and the requests of their manifests and segments, e.g. for HLS and DASH
+// quality of experience checks. A request is attributed to the session that started most recently, and the
+// sessions collected are reset on every navigation of the main frame.
+func (c *Puppet) CollectMediaDiagnostics() (err error) {
+	// the binding calls are reported to the session which added the binding
+	err = c.runSession(runtime.AddBinding(mediaBinding))
+	if err != nil {
+		return err
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(mediaListener).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+
+	type pending struct {
+		req  MediaRequest
+		typ  network.ResourceType
+		kind string
+	}
+	requests := map[network.RequestID]*pending{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				c.mu.Lock()
+				c.mediaSessions = nil
+				c.mu.Unlock()
+			}
+		case *runtime.EventBindingCalled:
+			if ev.Name != mediaBinding {
+				return
+			}
+			var v struct {
+				Session     int     `json:"session"`
+				Type        string  `json:"type"`
+				Src         string  `json:"src"`
+				CurrentTime float64 `json:"currentTime"`
+				Buffered    float64 `json:"buffered"`
+			}
+			if json.Unmarshal([]byte(ev.Payload), &v) != nil {
+				return
+			}
+			c.addMediaEvent(v.Session, v.Src, MediaEvent{
+				Type:        v.Type,
+				CurrentTime: time.Duration(v.CurrentTime * float64(time.Second)),
+				Buffered:    time.Duration(v.Buffered * float64(time.Second)),
+				Time:        time.Now(),
+			})
+		case *requestWillBeSent:
+			requests[ev.RequestID] = &pending{
+				req: MediaRequest{
+					URL:   ev.Request.URL,
+					Start: time.Now(),
+				},
+				typ:  ev.Type,
+				kind: mediaKind(ev.Request.URL, ev.Type, ""),
+			}
+		case *network.EventResponseReceived:
+			p, ok := requests[ev.RequestID]
+			if !ok {
+				return
+			}
+			p.req.StatusCode = int(ev.Response.Status)
+			if kind := mediaKind(p.req.URL, p.typ, ev.Response.MimeType); kind != "" {
+				p.kind = kind
+			}
+		case *network.EventLoadingFinished:
+			p, ok := requests[ev.RequestID]
+			delete(requests, ev.RequestID)
+			if !ok || p.kind == "" {
+				return
+			}
+			p.req.Kind = p.kind
+			p.req.Bytes = int64(ev.EncodedDataLength)
+			p.req.Duration = time.Since(p.req.Start)
+			c.addMediaRequest(p.req)
+		case *network.EventLoadingFailed:
+			p, ok := requests[ev.RequestID]
+			delete(requests, ev.RequestID)
+			if !ok || p.kind == "" {
+				return
+			}
+			p.req.Kind = p.kind
+			p.req.Failed = true
+			p.req.Duration = time.Since(p.req.Start)
+			c.addMediaRequest(p.req)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventRuntimeBindingCalled,
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkResponseReceived,
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkLoadingFailed,
+	)
+}
+
+// MediaSessions returns the playback sessions collected since the last navigation.
+func (c *Puppet) MediaSessions() []*MediaSession {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	sessions := make([]*MediaSession, 0, len(c.mediaSessions))
+	for _, s := range c.mediaSessions {
+		n := *s
+		n.Events = append([]MediaEvent(nil), s.Events...)
+		n.Requests = append([]MediaRequest(nil), s.Requests...)
+		sessions = append(sessions, &n)
+	}
+	return sessions
+}
+
+func (c *Puppet) addMediaEvent(id int, src string, ev MediaEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var s *MediaSession
+	for _, o := range c.mediaSessions {
+		if o.ID == id {
+			s = o
+		}
+	}
+	if s == nil {
+		s = &MediaSession{ID: id}
+		c.mediaSessions = append(c.mediaSessions, s)
+	}
+	if src != "" {
+		s.Source = src
+	}
+	s.Events = append(s.Events, ev)
+	switch ev.Type {
+	case "playing":
+		if !s.stallStart.IsZero() {
+			s.StallTime += ev.Time.Sub(s.stallStart)
+			s.stallStart = time.Time{}
+		}
+		s.started = true
+	case "waiting":
+		if s.started && s.stallStart.IsZero() {
+			s.Stalls++
+			s.stallStart = ev.Time
+		}
+	case "pause", "ended", "error":
+		if !s.stallStart.IsZero() {
+			s.StallTime += ev.Time.Sub(s.stallStart)
+			s.stallStart = time.Time{}
+		}
+	}
+}
+
+func (c *Puppet) addMediaRequest(req MediaRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.mediaSessions) == 0 {
+		c.mediaSessions = append(c.mediaSessions, &MediaSession{})
+	}
+	s := c.mediaSessions[len(c.mediaSessions)-1]
+	s.Requests = append(s.Requests, req)
+}
@@ -0,0 +1,31 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestCollectMediaDiagnostics(t *testing.T) {
+	p, b := newFakePuppet(t)
+	err := p.CollectMediaDiagnostics()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := b.callBinding(mediaBinding, `{"session":1,"type":"play","src":"blob:https://example.com/1","currentTime":0,"buffered":0}`); n != 1 {
+		t.Fatalf("binding called on %d connections, want 1", n)
+	}
+	eventually(t, func() bool {
+		return len(p.MediaSessions()) == 1
+	})
+	b.emit("Network.requestWillBeSent", `{"requestId":"1","loaderId":"L","documentURL":"https://example.com/","type":"XHR","request":{"url":"https://example.com/live.m3u8","method":"GET","headers":{}}}`)
+	b.emit("Network.responseReceived", `{"requestId":"1","loaderId":"L","type":"XHR","response":{"url":"https://example.com/live.m3u8","status":200,"statusText":"OK","headers":{},"mimeType":"application/vnd.apple.mpegurl","connectionReused":false,"connectionId":1,"encodedDataLength":0,"securityState":"secure"}}`)
+	b.emit("Network.loadingFinished", `{"requestId":"1","encodedDataLength":512}`)
+	eventually(t, func() bool {
+		sessions := p.MediaSessions()
+		return len(sessions) == 1 && len(sessions[0].Requests) == 1
+	})
+	req := p.MediaSessions()[0].Requests[0]
+	if req.Kind != "manifest" || req.StatusCode != 200 || req.Bytes != 512 {
+		t.Errorf("got request %+v", req)
+	}
+}
@@ -9,11 +9,13 @@ import (
 	"mime"
 	"mime/quotedprintable"
 	"net/textproto"
+	"strings"
 )
 
 type file struct {
 	ContentType string
 	Base        string
+	ContentID   string
 	Data        []byte
 }
 
@@ -33,10 +35,29 @@ func toFiles(src io.Reader) (files []*file, err error) {
 	}
 
 	boundary := []byte("--" + params["boundary"])
+	closing := append(append([]byte(nil), boundary...), "--"...)
 	var lines []byte
+	flush := func() error {
+		if len(bytes.TrimSpace(lines)) == 0 {
+			lines = lines[:0]
+			return nil
+		}
+		f, err := toFile(lines)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		lines = lines[:0]
+		return nil
+	}
 	for {
 		line, _, err := read.ReadLine()
 		if err == io.EOF {
+			// the last part may end with the closing boundary or the end of the archive
+			err = flush()
+			if err != nil {
+				return nil, err
+			}
 			return files, nil
 		}
 		if err != nil {
@@ -47,52 +68,59 @@ func toFiles(src io.Reader) (files []*file, err error) {
 			continue
 		}
 
-		if !bytes.Equal(line, boundary) {
+		if !bytes.Equal(line, boundary) && !bytes.Equal(line, closing) {
 			lines = append(lines, line...)
 			lines = append(lines, '\n')
 			continue
 		}
-		if len(lines) == 0 {
-			continue
+		err = flush()
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Equal(line, closing) {
+			return files, nil
 		}
+	}
+}
 
-		par := bufio.NewReader(bytes.NewBuffer(lines))
+// toFile decodes the MIME part.
+func toFile(part []byte) (*file, error) {
+	par := bufio.NewReader(bytes.NewReader(part))
 
-		tp := textproto.NewReader(par)
+	tp := textproto.NewReader(par)
 
-		hdr, err := tp.ReadMIMEHeader()
+	hdr, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	contentLocation := hdr.Get("Content-Location")
+
+	data, err := ioutil.ReadAll(par)
+	if err != nil {
+		return nil, err
+	}
+
+	switch hdr.Get("Content-Transfer-Encoding") {
+	case "base64":
+		buf := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		n, err := base64.StdEncoding.Decode(buf, data)
 		if err != nil {
 			return nil, err
 		}
-		contentLocation := hdr.Get("Content-Location")
-
-		data, err := ioutil.ReadAll(par)
+		data = buf[:n]
+	case "quoted-printable":
+		read := quotedprintable.NewReader(bytes.NewBuffer(data))
+		buf, err := ioutil.ReadAll(read)
 		if err != nil {
 			return nil, err
 		}
-
-		switch hdr.Get("Content-Transfer-Encoding") {
-		case "base64":
-			buf := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
-			n, err := base64.StdEncoding.Decode(buf, data)
-			if err != nil {
-				return nil, err
-			}
-			data = buf[:n]
-		case "quoted-printable":
-			read := quotedprintable.NewReader(bytes.NewBuffer(data))
-			buf, err := ioutil.ReadAll(read)
-			if err != nil {
-				return nil, err
-			}
-			data = buf
-		}
-
-		contentType := hdr.Get("Content-Type")
-		file := &file{contentType, contentLocation, data}
-
-		files = append(files, file)
-		lines = lines[:0]
+		data = buf
 	}
-	return files, nil
+
+	return &file{
+		ContentType: hdr.Get("Content-Type"),
+		Base:        contentLocation,
+		ContentID:   strings.Trim(hdr.Get("Content-ID"), "<>"),
+		Data:        data,
+	}, nil
 }
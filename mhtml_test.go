@@ -0,0 +1,61 @@
+package puppet
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMHTML = "From: <Saved by Blink>\r\n" +
+	"Subject: Example\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/related;\r\n" +
+	"\ttype=\"text/html\";\r\n" +
+	"\tboundary=\"----MultipartBoundary--abc----\"\r\n" +
+	"\r\n" +
+	"------MultipartBoundary--abc----\r\n" +
+	"Content-Type: text/html\r\n" +
+	"Content-ID: <frame-1@mhtml.blink>\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"Content-Location: https://example.com/\r\n" +
+	"\r\n" +
+	"<html><body class=3D\"page\">Hello</body></html>\r\n" +
+	"------MultipartBoundary--abc----\r\n" +
+	"Content-Type: text/css\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"Content-Location: https://example.com/style.css\r\n" +
+	"\r\n" +
+	"Ym9keSB7IGNvbG9yOiByZWQ7IH0=\r\n"
+
+func TestToFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		archive string
+	}{
+		{"closing boundary", testMHTML + "------MultipartBoundary--abc------\r\n"},
+		{"end of archive", testMHTML},
+	}
+	for _, tt := range tests {
+		files, err := toFiles(strings.NewReader(tt.archive))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(files) != 2 {
+			t.Errorf("%s: got %d files, want 2", tt.name, len(files))
+			continue
+		}
+		html, css := files[0], files[1]
+		if html.ContentType != "text/html" || html.Base != "https://example.com/" || html.ContentID != "frame-1@mhtml.blink" {
+			t.Errorf("%s: got html %+v", tt.name, html)
+		}
+		if got := strings.TrimSpace(string(html.Data)); got != `<html><body class="page">Hello</body></html>` {
+			t.Errorf("%s: got html data %q", tt.name, got)
+		}
+		if css.ContentType != "text/css" || css.Base != "https://example.com/style.css" {
+			t.Errorf("%s: got css %+v", tt.name, css)
+		}
+		if got := string(css.Data); got != "body { color: red; }" {
+			t.Errorf("%s: got css data %q", tt.name, got)
+		}
+	}
+}
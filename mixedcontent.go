@@ -0,0 +1,82 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/security"
+)
+
+// MixedContent is an insecure subresource requested by a secure page.
+type MixedContent struct {
+	URL string
+	// Type is the resource type of the subresource, e.g. "Image" or "Script".
+	Type string
+	// Blockable reports whether the subresource is blockable rather than optionally blockable.
+	Blockable bool
+	// Blocked reports whether the browser blocked the subresource.
+	Blocked bool
+}
+
+// CollectMixedContent starts collecting the insecure subresources requested by the current target,
+// the subresources collected are reset on every navigation of the main frame.
+func (c *Puppet) CollectMixedContent() (err error) {
+	requests := map[network.RequestID]*MixedContent{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				c.mu.Lock()
+				c.mixedContent = nil
+				c.mu.Unlock()
+			}
+		case *requestWillBeSent:
+			switch ev.Request.MixedContentType {
+			case security.MixedContentTypeBlockable, security.MixedContentTypeOptionallyBlockable:
+			default:
+				return
+			}
+			m := &MixedContent{
+				URL:       ev.Request.URL,
+				Type:      ev.Type.String(),
+				Blockable: ev.Request.MixedContentType == security.MixedContentTypeBlockable,
+			}
+			requests[ev.RequestID] = m
+			c.mu.Lock()
+			c.mixedContent = append(c.mixedContent, m)
+			c.mu.Unlock()
+		case *network.EventLoadingFailed:
+			m, ok := requests[ev.RequestID]
+			if !ok {
+				return
+			}
+			delete(requests, ev.RequestID)
+			if ev.BlockedReason == network.BlockedReasonMixedContent {
+				c.mu.Lock()
+				m.Blocked = true
+				c.mu.Unlock()
+			}
+		case *network.EventLoadingFinished:
+			delete(requests, ev.RequestID)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkLoadingFailed,
+		cdproto.EventNetworkLoadingFinished,
+	)
+}
+
+// MixedContent returns the insecure subresources requested since the last navigation.
+func (c *Puppet) MixedContent() []MixedContent {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	res := make([]MixedContent, 0, len(c.mixedContent))
+	for _, m := range c.mixedContent {
+		res = append(res, *m)
+	}
+	return res
+}
@@ -0,0 +1,179 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// ClickAt sends a mouse click event at the document coordinates x, y in CSS pixels,
+// the page is scrolled to the point first and the zoom of the visual viewport is compensated.
+func (c *Puppet) ClickAt(x, y float64) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		var point []float64
+		err := chromedp.Evaluate(fmt.Sprintf(`(function (x, y) {
+	var vv = window.visualViewport;
+	if (x < vv.pageLeft || x >= vv.pageLeft + vv.width || y < vv.pageTop || y >= vv.pageTop + vv.height) {
+		window.scrollTo(x - vv.width / 2, y - vv.height / 2);
+	}
+	return [(x - vv.pageLeft) * vv.scale, (y - vv.pageTop) * vv.scale];
+})(%v, %v)`, x, y), &point).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		return mouseClick(point[0], point[1], 1).
+			Do(ctx, h)
+	}))
+}
+
+// ClickPoint sends a mouse click event at the offset from the top left corner of the border box
+// of the first node matching the selector, e.g. for image maps and canvas hotspots.
+func (c *Puppet) ClickPoint(sel string, offsetX, offsetY float64) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx, chromedp.Tasks{
+		chromedp.ScrollIntoView(sel),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			var box *dom.BoxModel
+			err := chromedp.Dimensions(sel, &box, chromedp.NodeVisible).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			return mouseClick(box.Border[0]+offsetX, box.Border[1]+offsetY, 1).
+				Do(ctx, h)
+		}),
+	})
+}
+
+// TripleClick sends a mouse triple click event to the first node matching the selector, selecting its paragraph.
+func (c *Puppet) TripleClick(sel string) (err error) {
+	sel = c.selector(sel)
+	return c.clickCount(sel, 3)
+}
+
+// ClickCount sends count mouse clicks in a row to the center of the first node matching the selector.
+func (c *Puppet) ClickCount(sel string, count int) (err error) {
+	sel = c.selector(sel)
+	return c.clickCount(sel, count)
+}
+
+// clickCount sends count mouse clicks in a row to the center of the first node matching the selector,
+// the selector is resolved once so all the clicks land on the same node.
+func (c *Puppet) clickCount(sel string, count int) (err error) {
+	return c.run(c.ctx, chromedp.Tasks{
+		c.beforeAction(sel, true),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			var nodes []*cdp.Node
+			err := chromedp.Nodes(sel, &nodes, chromedp.NodeVisible).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			id := nodes[0].NodeID
+			err = scrollIntoViewIfNeeded(ctx, h, id)
+			if err != nil {
+				return err
+			}
+			box, err := dom.GetBoxModel().
+				WithNodeID(id).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			q := box.Content
+			return mouseClick((q[0]+q[2]+q[4]+q[6])/4, (q[1]+q[3]+q[5]+q[7])/4, int64(count)).
+				Do(ctx, h)
+		}),
+	})
+}
+
+// LongPress presses the center of the first node matching the selector for the duration,
+// with a touch when a mobile viewport is emulated and with the left mouse button otherwise.
+func (c *Puppet) LongPress(sel string, d time.Duration) (err error) {
+	sel = c.selector(sel)
+	v := c.Overrides().Viewport
+	touch := v != nil && v.Mobile
+	return c.run(c.ctx, chromedp.Tasks{
+		c.beforeAction(sel, true),
+		chromedp.ScrollIntoView(sel),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			x, y, err := nodeCenter(ctx, h, sel)
+			if err != nil {
+				return err
+			}
+			if touch {
+				err = input.DispatchTouchEvent(input.TouchStart, []*input.TouchPoint{{X: x, Y: y}}).
+					Do(ctx, h)
+			} else {
+				err = input.DispatchMouseEvent(input.MousePressed, x, y).
+					WithButton(input.ButtonLeft).
+					WithClickCount(1).
+					Do(ctx, h)
+			}
+			if err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(d):
+			}
+			if touch {
+				return input.DispatchTouchEvent(input.TouchEnd, []*input.TouchPoint{}).
+					Do(ctx, h)
+			}
+			return input.DispatchMouseEvent(input.MouseReleased, x, y).
+				WithButton(input.ButtonLeft).
+				WithClickCount(1).
+				Do(ctx, h)
+		}),
+	})
+}
+
+// nodeCenter returns the viewport coordinates of the center of the content box
+// of the first node matching the selector.
+func nodeCenter(ctx context.Context, h cdp.Executor, sel string) (x, y float64, err error) {
+	var box *dom.BoxModel
+	err = chromedp.Dimensions(sel, &box, chromedp.NodeVisible).
+		Do(ctx, h)
+	if err != nil {
+		return 0, 0, err
+	}
+	q := box.Content
+	return (q[0] + q[2] + q[4] + q[6]) / 4, (q[1] + q[3] + q[5] + q[7]) / 4, nil
+}
+
+// mouseClick is an action that presses and releases the left mouse button at the viewport coordinates x, y,
+// count times in a row.
+func mouseClick(x, y float64, count int64) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		err := input.DispatchMouseEvent(input.MouseMoved, x, y).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		for i := int64(1); i <= count; i++ {
+			err = input.DispatchMouseEvent(input.MousePressed, x, y).
+				WithButton(input.ButtonLeft).
+				WithClickCount(i).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			err = input.DispatchMouseEvent(input.MouseReleased, x, y).
+				WithButton(input.ButtonLeft).
+				WithClickCount(i).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
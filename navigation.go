@@ -0,0 +1,140 @@
+package puppet
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Decision is the decision of a navigation hook on a navigation request.
+type Decision struct {
+	// Cancel cancels the navigation.
+	Cancel bool
+	// URL redirects the navigation to the url if not empty.
+	URL string
+}
+
+// Navigation decisions.
+var (
+	// Allow lets the navigation proceed.
+	Allow = Decision{}
+	// Deny cancels the navigation.
+	Deny = Decision{Cancel: true}
+)
+
+// Rewrite redirects the navigation to the url.
+func Rewrite(url string) Decision {
+	return Decision{URL: url}
+}
+
+// OnNavigationRequest calls fn with the url of every navigation request of the main frame of the current target,
+// including redirects and navigations started by the page, e.g. by location.href or a meta refresh,
+// and cancels or redirects it as decided, e.g. to keep a crawler in scope.
+func (c *Puppet) OnNavigationRequest(fn func(url string) Decision) (err error) {
+	return c.intercept(nil, false, func(r *Request) {
+		if !r.IsNavigation || r.FrameID != mainFrameID(r.ctx, r.h) {
+			return
+		}
+		d := fn(r.URL)
+		switch {
+		case d.Cancel:
+			r.Fail()
+		case d.URL != "" && d.URL != r.URL:
+			r.Fulfill(http.StatusFound, http.Header{
+				"Location":      {d.URL},
+				"Cache-Control": {"no-store"},
+			}, nil)
+		}
+	})
+}
+
+type navigationPolicy struct {
+	allow []string
+	deny  []string
+}
+
+// navigationGuard is the navigation policy of a target, shared by the Puppets bound to it.
+type navigationGuard struct {
+	mu     sync.Mutex
+	policy *navigationPolicy
+	hooked bool
+}
+
+func (g *navigationGuard) get() *navigationPolicy {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.policy
+}
+
+// navigation returns the navigation guard of the Puppet.
+func (c *Puppet) navigation() *navigationGuard {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.navPolicy == nil {
+		c.navPolicy = &navigationGuard{}
+	}
+	return c.navPolicy
+}
+
+// SetNavigationPolicy cancels the navigations of the main frame of the current target to urls not matching
+// any of the allow patterns, or matching any of the deny patterns, e.g. to keep a crawler away from
+// logout links and external sites. A pattern is a glob where "*" matches any characters, it is matched
+// against the whole url if it contains "://", or else against the host, e.g. "*.example.com".
+// An empty allow list allows all urls, and a later call replaces the policy.
+func (c *Puppet) SetNavigationPolicy(allow, deny []string) (err error) {
+	g := c.navigation()
+	g.mu.Lock()
+	g.policy = &navigationPolicy{
+		allow: append([]string(nil), allow...),
+		deny:  append([]string(nil), deny...),
+	}
+	g.mu.Unlock()
+	return c.hookNavigationPolicy()
+}
+
+// hookNavigationPolicy enforces the navigation policy of the Puppet on its target, once.
+func (c *Puppet) hookNavigationPolicy() (err error) {
+	g := c.navigation()
+	g.mu.Lock()
+	hooked := g.hooked
+	g.hooked = true
+	g.mu.Unlock()
+	if hooked {
+		return nil
+	}
+	err = c.OnNavigationRequest(func(url string) Decision {
+		p := g.get()
+		if p == nil || p.allows(url) {
+			return Allow
+		}
+		return Deny
+	})
+	if err != nil {
+		g.mu.Lock()
+		g.policy = nil
+		g.hooked = false
+		g.mu.Unlock()
+	}
+	return err
+}
+
+func (p *navigationPolicy) allows(rawurl string) bool {
+	host := hostOf(rawurl)
+	if matchNavigation(p.deny, rawurl, host) {
+		return false
+	}
+	return len(p.allow) == 0 || matchNavigation(p.allow, rawurl, host)
+}
+
+func matchNavigation(patterns []string, rawurl, host string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "://") {
+			if globRegexp(pattern).MatchString(rawurl) {
+				return true
+			}
+		} else if globRegexp(strings.ToLower(pattern)).MatchString(host) {
+			return true
+		}
+	}
+	return false
+}
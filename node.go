@@ -0,0 +1,80 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/mailru/easyjson"
+)
+
+// callOn is an action that calls the javascript function with the first node matching the selector as this,
+// unmarshaling the result of the function to res.
+func callOn(sel string, function string, res interface{}, args ...interface{}) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		var nodes []*cdp.Node
+		err := chromedp.Nodes(sel, &nodes, chromedp.NodeReady).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		return callOnNode(ctx, h, nodes[0].NodeID, function, res, args...)
+	})
+}
+
+// scrollIntoViewIfNeeded scrolls the node to the center of the viewport unless it is already visible.
+func scrollIntoViewIfNeeded(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+	return callOnNode(ctx, h, id, `function () {
+	if (this.scrollIntoViewIfNeeded) {
+		this.scrollIntoViewIfNeeded(true);
+	} else {
+		this.scrollIntoView({ block: "center", inline: "center" });
+	}
+}`, nil)
+}
+
+// callOnNode calls the javascript function with the node as this, unmarshaling the result of the function to res.
+func callOnNode(ctx context.Context, h cdp.Executor, id cdp.NodeID, function string, res interface{}, args ...interface{}) (err error) {
+	obj, err := dom.ResolveNode().
+		WithNodeID(id).
+		Do(ctx, h)
+	if err != nil {
+		return err
+	}
+	defer runtime.ReleaseObject(obj.ObjectID).Do(ctx, h)
+
+	arguments := make([]*runtime.CallArgument, 0, len(args))
+	for _, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return err
+		}
+		arguments = append(arguments, &runtime.CallArgument{
+			Value: easyjson.RawMessage(data),
+		})
+	}
+
+	v, exp, err := runtime.CallFunctionOn(function).
+		WithObjectID(obj.ObjectID).
+		WithArguments(arguments).
+		WithReturnByValue(true).
+		WithAwaitPromise(true).
+		Do(ctx, h)
+	if err != nil {
+		return err
+	}
+	if exp != nil {
+		return exp
+	}
+	if res == nil || v.Type == runtime.TypeUndefined {
+		return nil
+	}
+	if len(v.Value) == 0 {
+		return fmt.Errorf("unexpected %s result", v.Type)
+	}
+	return json.Unmarshal(v.Value, res)
+}
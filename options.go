@@ -0,0 +1,120 @@
+package puppet
+
+import (
+	"fmt"
+
+	"github.com/chromedp/cdproto/security"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/runner"
+)
+
+// Option is an option of NewPuppet.
+type Option func(*options)
+
+type options struct {
+	// flags are the command line flags of the browser launched by the Puppet,
+	// they have no effect when connecting to a running browser.
+	flags []runner.CommandLineOption
+	// port is the remote debugging port of the launched browser, 9222 by default.
+	port int
+	// actions are run against the active target once connected.
+	actions []chromedp.Action
+	// logger receives the actions, navigations and errors of the Puppet.
+	logger Logger
+	// tracer traces the actions of the Puppet.
+	tracer Tracer
+	// logCDP logs the CDP traffic to the logger.
+	logCDP bool
+	// cleanups are called once the Puppet is closed.
+	cleanups []func()
+	// err is the first error of the options, returned by NewPuppet.
+	err error
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		port: 9222,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// cleanup calls the cleanups of the options.
+func (o *options) cleanup() {
+	for _, cleanup := range o.cleanups {
+		cleanup()
+	}
+}
+
+// WithIgnoreCertErrors ignores all certificate errors, e.g. of the self-signed certificates of staging environments.
+func WithIgnoreCertErrors() Option {
+	return func(o *options) {
+		o.flags = append(o.flags, runner.Flag("ignore-certificate-errors", true))
+		o.actions = append(o.actions, security.SetIgnoreCertificateErrors(true))
+	}
+}
+
+// WithPort sets the remote debugging port of the launched browser, 9222 by default,
+// so several browsers can run side by side. Without a url NewPuppet connects to a browser
+// already listening on the port instead of launching one.
+func WithPort(port int) Option {
+	return func(o *options) {
+		o.port = port
+		o.flags = append(o.flags, runner.Flag("remote-debugging-port", port))
+	}
+}
+
+// WithHostRules sets the host resolver rules of the launched browser,
+// e.g. "MAP example.com 127.0.0.1, MAP *.example.com 127.0.0.1:8080".
+func WithHostRules(rules string) Option {
+	return func(o *options) {
+		o.flags = append(o.flags, runner.Flag("host-resolver-rules", rules))
+	}
+}
+
+// WithLogger sends the actions, navigations and errors of the Puppet to the logger.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithTracer traces the actions of the Puppet with the tracer,
+// the actions are started with the context passed to WithContext, if any.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+// WithCDPLogging sends the CDP messages exchanged with the browser to the logger at the debug level,
+// it is very verbose and has no effect without WithLogger.
+func WithCDPLogging() Option {
+	return func(o *options) {
+		o.logCDP = true
+	}
+}
+
+// cdpOptions returns the options of the CDP instance.
+func (o *options) cdpOptions() []chromedp.Option {
+	if o.logger == nil {
+		return nil
+	}
+	l := o.logger
+	opts := []chromedp.Option{
+		chromedp.WithLogf(func(format string, v ...interface{}) {
+			l.Log(LogInfo, fmt.Sprintf(format, v...))
+		}),
+		chromedp.WithErrorf(func(format string, v ...interface{}) {
+			l.Log(LogError, fmt.Sprintf(format, v...))
+		}),
+	}
+	if o.logCDP {
+		opts = append(opts, chromedp.WithDebugf(func(format string, v ...interface{}) {
+			l.Log(LogDebug, "cdp", "message", fmt.Sprintf(format, v...))
+		}))
+	}
+	return opts
+}
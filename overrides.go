@@ -0,0 +1,198 @@
+package puppet
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// Overrides are the emulation and network overrides set through the Puppet.
+type Overrides struct {
+	// Headers are the extra HTTP headers sent with every request.
+	Headers map[string]interface{}
+	// Permissions are the permissions granted by origin, to all the targets of the browser.
+	Permissions map[string][]Permission
+	// UserAgent is the user agent reported to pages.
+	UserAgent string
+	// AcceptLanguage is the Accept-Language header and navigator.languages reported to pages.
+	AcceptLanguage string
+	// Media is the emulated CSS media type.
+	Media string
+	// MediaFeatures are the emulated CSS media features.
+	MediaFeatures map[string]string
+	// Viewport is the emulated device metrics.
+	Viewport *Viewport
+	// BypassCSP reports whether the Content-Security-Policy of pages is bypassed.
+	BypassCSP bool
+	// JavaScriptDisabled reports whether the execution of the scripts of pages is disabled.
+	JavaScriptDisabled bool
+}
+
+// overrideStore is the overrides of the targets of a browser, shared by the Puppets driving them.
+type overrideStore struct {
+	mu      sync.Mutex
+	targets map[string]*Overrides
+	// permissions are granted to the whole browser.
+	permissions map[string][]Permission
+}
+
+// storedOverrides returns the overrides of the targets of the browser.
+func (c *Puppet) storedOverrides() *overrideStore {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.overrides == nil {
+		c.overrides = &overrideStore{}
+	}
+	return c.overrides
+}
+
+// currentTarget returns the id of the target the Puppet is bound to by Tab, or else of the active target.
+func (c *Puppet) currentTarget() (id string, err error) {
+	if c.target != "" {
+		return c.target, nil
+	}
+	err = c.cdp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		for _, t := range c.cdp.ListTargets() {
+			if c.cdp.GetHandlerByID(t) == h {
+				id = t
+				return nil
+			}
+		}
+		return errors.New("no active target")
+	}))
+	return id, err
+}
+
+// Overrides returns the emulation and network overrides currently active in the current target.
+func (c *Puppet) Overrides() Overrides {
+	s := c.storedOverrides()
+	id, err := c.currentTarget()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var o Overrides
+	if t := s.targets[id]; err == nil && t != nil {
+		o = t.clone()
+	}
+	if s.permissions != nil {
+		o.Permissions = make(map[string][]Permission, len(s.permissions))
+		for k, v := range s.permissions {
+			o.Permissions[k] = append([]Permission(nil), v...)
+		}
+	}
+	return o
+}
+
+// updateOverrides calls fn to update the overrides of the current target.
+func (c *Puppet) updateOverrides(fn func(o *Overrides)) {
+	s := c.storedOverrides()
+	id, err := c.currentTarget()
+	if err != nil {
+		return
+	}
+	live := map[string]bool{}
+	for _, t := range c.cdp.ListTargets() {
+		live[t] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// forget the closed targets
+	for t := range s.targets {
+		if !live[t] {
+			delete(s.targets, t)
+		}
+	}
+	if s.targets == nil {
+		s.targets = map[string]*Overrides{}
+	}
+	o := s.targets[id]
+	if o == nil {
+		o = &Overrides{}
+		s.targets[id] = o
+	}
+	fn(o)
+}
+
+// clone returns a deep copy of the overrides.
+func (o Overrides) clone() Overrides {
+	if o.Headers != nil {
+		headers := make(map[string]interface{}, len(o.Headers))
+		for k, v := range o.Headers {
+			headers[k] = v
+		}
+		o.Headers = headers
+	}
+	if o.Permissions != nil {
+		perms := make(map[string][]Permission, len(o.Permissions))
+		for k, v := range o.Permissions {
+			perms[k] = append([]Permission(nil), v...)
+		}
+		o.Permissions = perms
+	}
+	if o.Viewport != nil {
+		v := *o.Viewport
+		o.Viewport = &v
+	}
+	if o.MediaFeatures != nil {
+		features := make(map[string]string, len(o.MediaFeatures))
+		for k, v := range o.MediaFeatures {
+			features[k] = v
+		}
+		o.MediaFeatures = features
+	}
+	return o
+}
+
+// ResetOverrides clears all emulation and network overrides, returning the current target to a pristine state,
+// including the overrides made by interception, e.g. by HostOverride, UseCredentialVault, ModifyRequestHeaders
+// and SetNavigationPolicy, and the speculation rules disabled by SetSpeculationRules.
+func (c *Puppet) ResetOverrides() (err error) {
+	err = c.run(c.ctx, chromedp.Tasks{
+		c.withNetwork(network.SetExtraHTTPHeaders(network.Headers{})),
+		browser.ResetPermissions(),
+		emulation.SetUserAgentOverride(""),
+		emulatedMedia("", nil),
+		emulation.ClearDeviceMetricsOverride(),
+		page.SetBypassCSP(false),
+		emulation.SetScriptExecutionDisabled(false),
+	})
+	if err != nil {
+		return err
+	}
+	err = c.SetSpeculationRules(true)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	var interceptors []*interceptor
+	for _, i := range c.interceptors {
+		if !i.override {
+			interceptors = append(interceptors, i)
+		}
+	}
+	c.interceptors = interceptors
+	c.mu.Unlock()
+	// the navigation hook stays, allowing all navigations
+	g := c.navigation()
+	g.mu.Lock()
+	g.policy = nil
+	g.mu.Unlock()
+
+	s := c.storedOverrides()
+	id, err := c.currentTarget()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.targets, id)
+	s.permissions = nil
+	s.mu.Unlock()
+	return nil
+}
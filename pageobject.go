@@ -0,0 +1,91 @@
+package puppet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// PageObject maps the names of the elements of a page, e.g. "loginButton", to their selectors,
+// so the selectors live in one place when the markup changes.
+type PageObject map[string]string
+
+// UsePageObject registers the names of the page object, all actions taking a selector accept them afterwards,
+// e.g. c.Click("loginButton"). Names registered before are replaced.
+func (c *Puppet) UsePageObject(po PageObject) {
+	selectors := PageObject{}
+	c.mu.Lock()
+	for name, sel := range c.selectors {
+		selectors[name] = sel
+	}
+	for name, sel := range po {
+		selectors[name] = sel
+	}
+	c.selectors = selectors
+	c.mu.Unlock()
+}
+
+// ValidatePageObject checks that the selectors of all registered names match a node of the current page,
+// and returns an error listing the names that don't.
+func (c *Puppet) ValidatePageObject() (err error) {
+	c.mu.Lock()
+	selectors := c.selectors
+	c.mu.Unlock()
+
+	data, err := json.Marshal(selectors)
+	if err != nil {
+		return err
+	}
+	var missing []string
+	found := map[string]bool{}
+	err = c.run(c.ctx,
+		chromedp.Evaluate(fmt.Sprintf(`(function (selectors) {
+	var found = {};
+	Object.keys(selectors).forEach(function (name) {
+		var sel = selectors[name];
+		try {
+			if (sel[0] === "/" || sel[0] === "(") {
+				found[name] = !!document.evaluate(sel, document, null, XPathResult.FIRST_ORDERED_NODE_TYPE, null).singleNodeValue;
+			} else {
+				found[name] = !!document.querySelector(sel);
+			}
+		} catch (e) {
+			found[name] = false;
+		}
+	});
+	return found;
+})(%s)`, data), &found))
+	if err != nil {
+		return err
+	}
+	for name, sel := range selectors {
+		if !found[name] {
+			var suggestions []string
+			c.run(c.ctx,
+				c.suggestSelectors(sel, 3, &suggestions))
+			if len(suggestions) != 0 {
+				missing = append(missing, fmt.Sprintf("%s (%s, did you mean %s)", name, sel, strings.Join(suggestions, " or ")))
+			} else {
+				missing = append(missing, fmt.Sprintf("%s (%s)", name, sel))
+			}
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("page object: selectors not found: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// selector returns the selector registered under the name, or the name itself if it is a selector.
+func (c *Puppet) selector(name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sel, ok := c.selectors[name]; ok {
+		return sel
+	}
+	return name
+}
@@ -0,0 +1,118 @@
+package puppet
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PDFAOptions configures the conversion of a PDF toward PDF/A.
+type PDFAOptions struct {
+	// Level is the PDF/A part, 1, 2 or 3, 2 if zero.
+	Level int
+	// ICCProfile is the path of the RGB ICC profile embedded as the output intent, e.g. "/usr/share/color/icc/sRGB.icc".
+	ICCProfile string
+	// Title is the title written to the document info and the XMP metadata.
+	Title string
+	// Ghostscript is the path of the Ghostscript executable, "gs" if empty.
+	Ghostscript string
+}
+
+// PDFA prints the page as PDF and converts it toward PDF/A.
+func (c *Puppet) PDFA(opts PDFAOptions) (res []byte, err error) {
+	pdf, err := c.PDF()
+	if err != nil {
+		return nil, err
+	}
+	return ConvertPDFA(pdf, opts)
+}
+
+// ConvertPDFA converts the PDF toward PDF/A with Ghostscript, which embeds the fonts,
+// the ICC profile as the output intent, and the XMP metadata.
+func ConvertPDFA(pdf []byte, opts PDFAOptions) (res []byte, err error) {
+	if opts.ICCProfile == "" {
+		return nil, fmt.Errorf("pdfa: ICC profile required")
+	}
+	icc, err := filepath.Abs(opts.ICCProfile)
+	if err != nil {
+		return nil, err
+	}
+	level := opts.Level
+	if level == 0 {
+		level = 2
+	}
+	gs := opts.Ghostscript
+	if gs == "" {
+		gs = "gs"
+	}
+
+	dir, err := ioutil.TempDir("", "puppet-pdfa")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.pdf")
+	out := filepath.Join(dir, "out.pdf")
+	def := filepath.Join(dir, "pdfa_def.ps")
+	err = ioutil.WriteFile(in, pdf, 0600)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(def, []byte(pdfaDef(icc, opts.Title)), 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(gs,
+		fmt.Sprintf("-dPDFA=%d", level),
+		"-dBATCH",
+		"-dNOPAUSE",
+		"-dNOOUTERSAVE",
+		"-dQUIET",
+		"-dPDFACompatibilityPolicy=1",
+		"-dEmbedAllFonts=true",
+		"-sColorConversionStrategy=RGB",
+		"-sProcessColorModel=DeviceRGB",
+		"-sDEVICE=pdfwrite",
+		"--permit-file-read="+icc,
+		"-sOutputFile="+out,
+		def,
+		in,
+	)
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("pdfa: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return ioutil.ReadFile(out)
+}
+
+// pdfaDef returns the PostScript prologue defining the document info and the output intent.
+func pdfaDef(icc string, title string) string {
+	return `%!
+[ /Title ` + psString(title) + ` /DOCINFO pdfmark
+[/_objdef {icc_PDFA} /type /stream /OBJ pdfmark
+[{icc_PDFA} << /N 3 >> /PUT pdfmark
+[{icc_PDFA} ` + psString(icc) + ` (r) file /PUT pdfmark
+[/_objdef {OutputIntent_PDFA} /type /dict /OBJ pdfmark
+[{OutputIntent_PDFA} <<
+  /Type /OutputIntent
+  /S /GTS_PDFA1
+  /DestOutputProfile {icc_PDFA}
+  /OutputConditionIdentifier (sRGB)
+>> /PUT pdfmark
+[{Catalog} << /OutputIntents [ {OutputIntent_PDFA} ] >> /PUT pdfmark
+`
+}
+
+// psString quotes the string as a PostScript string literal.
+func psString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return "(" + r.Replace(s) + ")"
+}
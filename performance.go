@@ -0,0 +1,102 @@
+package puppet
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/performance"
+	"github.com/chromedp/chromedp"
+)
+
+// Metrics returns the run-time metrics of the current target by name, e.g. "JSHeapUsedSize", "Nodes" or "LayoutCount".
+func (c *Puppet) Metrics() (metrics map[string]float64, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		err := performance.Enable().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		res, err := performance.GetMetrics().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		metrics = make(map[string]float64, len(res))
+		for _, m := range res {
+			metrics[m.Name] = m.Value
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// NavigationTiming is the timing of the navigation of the current page, relative to its start.
+// The timings not available, e.g. before the page has loaded or for paints not supported, are zero.
+type NavigationTiming struct {
+	// TTFB is the time to the first byte of the response.
+	TTFB                   time.Duration
+	DOMContentLoaded       time.Duration
+	Load                   time.Duration
+	FirstContentfulPaint   time.Duration
+	LargestContentfulPaint time.Duration
+}
+
+// NavigationTiming returns the timing of the navigation of the current page by the Navigation Timing
+// and the Paint Timing APIs.
+func (c *Puppet) NavigationTiming() (timing *NavigationTiming, err error) {
+	var res struct {
+		TTFB                   float64 `json:"ttfb"`
+		DOMContentLoaded       float64 `json:"domContentLoaded"`
+		Load                   float64 `json:"load"`
+		FirstContentfulPaint   float64 `json:"fcp"`
+		LargestContentfulPaint float64 `json:"lcp"`
+	}
+	err = c.run(c.ctx,
+		chromedp.Evaluate(`new Promise(function (resolve) {
+	var res = {};
+	var nav = performance.getEntriesByType("navigation")[0];
+	if (nav) {
+		res.ttfb = nav.responseStart;
+		res.domContentLoaded = nav.domContentLoadedEventEnd;
+		res.load = nav.loadEventEnd;
+	} else if (performance.timing) {
+		var t = performance.timing;
+		res.ttfb = Math.max(t.responseStart - t.navigationStart, 0);
+		res.domContentLoaded = Math.max(t.domContentLoadedEventEnd - t.navigationStart, 0);
+		res.load = Math.max(t.loadEventEnd - t.navigationStart, 0);
+	}
+	performance.getEntriesByType("paint").forEach(function (e) {
+		if (e.name === "first-contentful-paint") {
+			res.fcp = e.startTime;
+		}
+	});
+	try {
+		new PerformanceObserver(function (list) {
+			var entries = list.getEntries();
+			if (entries.length) {
+				res.lcp = entries[entries.length - 1].startTime;
+			}
+		}).observe({ type: "largest-contentful-paint", buffered: true });
+	} catch (e) {
+	}
+	// the buffered entries are delivered asynchronously
+	setTimeout(function () { resolve(res); }, 50);
+})`, &res, awaitPromise))
+	if err != nil {
+		return nil, err
+	}
+	ms := func(v float64) time.Duration {
+		return time.Duration(v * float64(time.Millisecond))
+	}
+	return &NavigationTiming{
+		TTFB:                   ms(res.TTFB),
+		DOMContentLoaded:       ms(res.DOMContentLoaded),
+		Load:                   ms(res.Load),
+		FirstContentfulPaint:   ms(res.FirstContentfulPaint),
+		LargestContentfulPaint: ms(res.LargestContentfulPaint),
+	}, nil
+}
@@ -0,0 +1,49 @@
+package puppet
+
+import (
+	"github.com/chromedp/cdproto/browser"
+)
+
+// Permission is a browser permission that can be granted to an origin.
+type Permission = browser.PermissionType
+
+// Permissions commonly prompted for by pages.
+const (
+	PermissionNotifications  = browser.PermissionTypeNotifications
+	PermissionGeolocation    = browser.PermissionTypeGeolocation
+	PermissionClipboardRead  = browser.PermissionTypeClipboardRead
+	PermissionClipboardWrite = browser.PermissionTypeClipboardWrite
+	PermissionCamera         = browser.PermissionTypeVideoCapture
+	PermissionMicrophone     = browser.PermissionTypeAudioCapture
+)
+
+// GrantPermissions grants the permissions to the origin, rejecting all others.
+func (c *Puppet) GrantPermissions(origin string, perms ...Permission) (err error) {
+	err = c.run(c.ctx,
+		browser.GrantPermissions(origin, perms))
+	if err != nil {
+		return err
+	}
+	s := c.storedOverrides()
+	s.mu.Lock()
+	if s.permissions == nil {
+		s.permissions = map[string][]Permission{}
+	}
+	s.permissions[origin] = append([]Permission(nil), perms...)
+	s.mu.Unlock()
+	return nil
+}
+
+// ResetPermissions resets all permission management for all origins.
+func (c *Puppet) ResetPermissions() (err error) {
+	err = c.run(c.ctx,
+		browser.ResetPermissions())
+	if err != nil {
+		return err
+	}
+	s := c.storedOverrides()
+	s.mu.Lock()
+	s.permissions = nil
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,29 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestGrantPermissions(t *testing.T) {
+	p, b := newFakePuppet(t)
+
+	err := p.GrantPermissions("https://example.com", PermissionClipboardRead, PermissionClipboardWrite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"origin":"https://example.com","permissions":["clipboardRead","clipboardWrite"]}`
+	if got := b.lastParams("Browser.grantPermissions"); got != want {
+		t.Fatalf("got params %s, want %s", got, want)
+	}
+	if got := p.Overrides().Permissions["https://example.com"]; len(got) != 2 {
+		t.Fatalf("got overrides permissions %v, want 2", got)
+	}
+
+	err = p.ResetPermissions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.called("Browser.resetPermissions") != 1 {
+		t.Fatal("permissions not reset")
+	}
+}
@@ -0,0 +1,107 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// PrerenderEvent is a status change of a prerender attempt started by speculation rules.
+type PrerenderEvent struct {
+	URL string
+	// Status is the status of the attempt, e.g. "Running", "Ready", "Success" once the prerendered page is activated, or "Failure".
+	Status string
+	// FinalStatus is the reason the attempt ended, e.g. "Activated".
+	FinalStatus string
+}
+
+// eventPreloadPrerenderStatusUpdated is fired on the status changes of the prerender attempts, it is unknown to cdproto.
+const eventPreloadPrerenderStatusUpdated cdproto.MethodType = "Preload.prerenderStatusUpdated"
+
+// prerenderStatusUpdated is the event of eventPreloadPrerenderStatusUpdated.
+type prerenderStatusUpdated struct {
+	Key *struct {
+		URL string `json:"url"`
+	} `json:"key"`
+	Status          string `json:"status"`
+	PrerenderStatus string `json:"prerenderStatus"`
+}
+
+// OnPrerender calls fn with every status change of the prerender attempts of the current target,
+// including the activation of prerendered pages.
+func (c *Puppet) OnPrerender(fn func(PrerenderEvent)) (err error) {
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		updated, ok := ev.(*prerenderStatusUpdated)
+		if !ok {
+			return
+		}
+		e := PrerenderEvent{
+			Status:      updated.Status,
+			FinalStatus: updated.PrerenderStatus,
+		}
+		if updated.Key != nil {
+			e.URL = updated.Key.URL
+		}
+		fn(e)
+	}, eventPreloadPrerenderStatusUpdated)
+}
+
+const disableSpeculationRules = `(function () {
+	if (window.HTMLScriptElement && HTMLScriptElement.supports) {
+		var supports = HTMLScriptElement.supports;
+		HTMLScriptElement.supports = function (type) {
+			return type === "speculationrules" ? false : supports.call(this, type);
+		};
+	}
+	new MutationObserver(function (records) {
+		records.forEach(function (record) {
+			record.addedNodes.forEach(function (node) {
+				if (node.nodeName === "SCRIPT" && node.type === "speculationrules") {
+					node.remove();
+				}
+			});
+		});
+	}).observe(document, { childList: true, subtree: true });
+})();`
+
+// SetSpeculationRules enables or disables the speculation rules of the pages loaded afterwards in the current target,
+// so prerendering and prefetching can be tested deterministically. Speculation rules are enabled by default.
+func (c *Puppet) SetSpeculationRules(enabled bool) (err error) {
+	c.mu.Lock()
+	id := c.speculationScript
+	c.mu.Unlock()
+
+	if enabled {
+		if id == "" {
+			return nil
+		}
+		err = c.run(c.ctx,
+			page.RemoveScriptToEvaluateOnNewDocument(id))
+		if err != nil {
+			return err
+		}
+		c.mu.Lock()
+		c.speculationScript = ""
+		c.mu.Unlock()
+		return nil
+	}
+
+	if id != "" {
+		return nil
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		id, err = page.AddScriptToEvaluateOnNewDocument(disableSpeculationRules).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.speculationScript = id
+	c.mu.Unlock()
+	return nil
+}
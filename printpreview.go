@@ -0,0 +1,156 @@
+package puppet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// PaperSize is the size of a paper in inches.
+type PaperSize struct {
+	Width  float64
+	Height float64
+}
+
+// Paper sizes.
+var (
+	PaperA3     = PaperSize{Width: 11.69, Height: 16.54}
+	PaperA4     = PaperSize{Width: 8.27, Height: 11.69}
+	PaperA5     = PaperSize{Width: 5.83, Height: 8.27}
+	PaperLetter = PaperSize{Width: 8.5, Height: 11}
+	PaperLegal  = PaperSize{Width: 8.5, Height: 14}
+)
+
+// PrintPreviewOptions configures PrintPreviewScreenshot.
+type PrintPreviewOptions struct {
+	// Paper is the paper size, PaperA4 if zero, a CSS @page size takes precedence.
+	Paper PaperSize
+	// Landscape prints in landscape orientation.
+	Landscape bool
+	// Margin is the margin of every side in inches, 0.4 if zero and none if negative.
+	Margin float64
+	// Background prints the background graphics.
+	Background bool
+	// DPI is the resolution of the images, 96 if zero.
+	DPI int
+	// Ghostscript is the path of the Ghostscript executable, "gs" if empty.
+	Ghostscript string
+}
+
+// PrintPreviewScreenshot lays out the page for print with the print stylesheets and returns a PNG image
+// of every printed page, e.g. to verify print CSS. The page is paginated by the browser as for printing,
+// and the pages are rasterized with Ghostscript.
+func (c *Puppet) PrintPreviewScreenshot(opts PrintPreviewOptions) (res [][]byte, err error) {
+	paper := opts.Paper
+	if paper.Width == 0 || paper.Height == 0 {
+		paper = PaperA4
+	}
+	margin := opts.Margin
+	if margin == 0 {
+		margin = 0.4
+	} else if margin < 0 {
+		margin = 0
+	}
+	dpi := opts.DPI
+	if dpi == 0 {
+		dpi = 96
+	}
+
+	// let the scripts of the page respond to the print media, as before printing
+	o := c.Overrides()
+	media, features := o.Media, o.MediaFeatures
+	err = c.run(c.ctx,
+		emulatedMedia("print", features))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		errRestore := c.run(c.ctx,
+			emulatedMedia(media, features))
+		if err == nil {
+			err = errRestore
+		}
+	}()
+
+	var pdf []byte
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		pdf, err = page.PrintToPDF().
+			WithPaperWidth(paper.Width).
+			WithPaperHeight(paper.Height).
+			WithMarginTop(margin).
+			WithMarginBottom(margin).
+			WithMarginRight(margin).
+			WithMarginLeft(margin).
+			WithLandscape(opts.Landscape).
+			WithPrintBackground(opts.Background).
+			WithPreferCSSPageSize(true).
+			Do(ctx, h)
+		return err
+	}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rasterizePDF(pdf, dpi, opts.Ghostscript)
+}
+
+// rasterizePDF renders every page of the PDF as a PNG image with Ghostscript.
+func rasterizePDF(pdf []byte, dpi int, gs string) (res [][]byte, err error) {
+	if gs == "" {
+		gs = "gs"
+	}
+	dir, err := ioutil.TempDir("", "puppet-print")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	in := filepath.Join(dir, "in.pdf")
+	err = ioutil.WriteFile(in, pdf, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command(gs,
+		"-dBATCH",
+		"-dNOPAUSE",
+		"-dSAFER",
+		"-dQUIET",
+		"-dTextAlphaBits=4",
+		"-dGraphicsAlphaBits=4",
+		fmt.Sprintf("-r%d", dpi),
+		"-sDEVICE=png16m",
+		"-sOutputFile="+filepath.Join(dir, "page-%04d.png"),
+		in,
+	)
+	cmd.Stderr = &stderr
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("print preview: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	pages, err := filepath.Glob(filepath.Join(dir, "page-*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pages)
+	for _, name := range pages {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, data)
+	}
+	return res, nil
+}
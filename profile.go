@@ -0,0 +1,45 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/profiler"
+	"github.com/chromedp/chromedp"
+)
+
+// StartJSProfile starts profiling the JavaScript of the current target, sampling every interval in microseconds,
+// or the default of the browser if zero.
+func (c *Puppet) StartJSProfile(interval int64) (err error) {
+	actions := chromedp.Tasks{
+		profiler.Enable(),
+	}
+	if interval > 0 {
+		actions = append(actions, profiler.SetSamplingInterval(interval))
+	}
+	return c.run(c.ctx, append(actions,
+		profiler.Start()))
+}
+
+// StopJSProfile stops profiling the JavaScript of the current target, and returns the CPU profile as JSON,
+// in the .cpuprofile format the Performance panel of the DevTools loads.
+func (c *Puppet) StopJSProfile() (res []byte, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		profile, err := profiler.Stop().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		res, err = json.Marshal(profile)
+		if err != nil {
+			return err
+		}
+		return profiler.Disable().
+			Do(ctx, h)
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,40 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chromedp/cdproto/cdp"
+)
+
+// execute executes the command of the protocol, for the commands and the parameters newer than cdproto,
+// unmarshaling the result to res unless it is nil.
+func execute(ctx context.Context, h cdp.Executor, method string, params, res interface{}) error {
+	var p json.Marshaler
+	if params != nil {
+		p = jsonParams{params}
+	}
+	var r json.Unmarshaler
+	if res != nil {
+		r = &jsonResult{res}
+	}
+	return h.Execute(ctx, method, p, r)
+}
+
+// jsonParams marshals the parameters of a command with encoding/json.
+type jsonParams struct {
+	v interface{}
+}
+
+func (p jsonParams) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.v)
+}
+
+// jsonResult unmarshals the result of a command with encoding/json.
+type jsonResult struct {
+	v interface{}
+}
+
+func (r *jsonResult) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, r.v)
+}
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"sync"
 	"time"
 	"unsafe"
 
 	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
 	"github.com/chromedp/cdproto/network"
 	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
 	"github.com/chromedp/chromedp/client"
 	"github.com/chromedp/chromedp/runner"
@@ -22,57 +25,303 @@ type Puppet struct {
 	cli    *client.Client
 	ctx    context.Context
 	cancel func()
+	target string
+	retry  *RetryPolicy
+	logger Logger
+	tracer Tracer
+
+	// endpoint is the DevTools HTTP endpoint of the browser, e.g. "http://localhost:9222/json".
+	endpoint string
+	// view reports whether the Puppet is a view of another one by WithRetry, closing it releases nothing.
+	view bool
+
+	mu        sync.Mutex
+	overrides *overrideStore
+	sessions  *sessionStore
+	contexts  map[string]*ContextOptions
+	selectors PageObject
+	secrets   SecretsProvider
+	navPolicy *navigationGuard
+
+	labels     map[string]Labels
+	targetSeen map[string]time.Time
+
+	cspViolations []*CSPViolation
+	mixedContent  []*MixedContent
+	thirdParties  map[string]*ThirdParty
+	storageWrites []StorageWrite
+	mediaSessions []*MediaSession
+
+	securityDetails *SecurityDetails
+
+	console           []ConsoleMessage
+	collectingConsole bool
+	failureStore      ArtifactStore
+	capturingFailure  bool
+
+	clickStability time.Duration
+	actionability  *Actionability
+	healing        bool
+	fingerprints   map[string]*nodeFingerprint
+	maxTargets     int
+	watchingMax    bool
+
+	speculationScript page.ScriptIdentifier
+
+	standby        []string
+	standbySize    int
+	standbyWarming int
+	standbyScripts []string
+
+	discovering   bool
+	targetCreated []func(TargetInfo)
+	targetWaiters map[chan TargetInfo]struct{}
+
+	intercepting      bool
+	interceptResponse bool
+	interceptors      []*interceptor
 }
 
 // NewPuppet creates and starts a new CDP instance
-func NewPuppet(url string) (*Puppet, error) {
+func NewPuppet(url string, opts ...Option) (*Puppet, error) {
+	opt := newOptions(opts)
+	if opt.err != nil {
+		opt.cleanup()
+		return nil, opt.err
+	}
 
-	p := &Puppet{}
+	p := &Puppet{
+		logger: opt.logger,
+		tracer: opt.tracer,
+	}
 
 	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.sessions = &sessionStore{ctx: p.ctx}
+	if len(opt.cleanups) != 0 {
+		go func() {
+			<-p.ctx.Done()
+			opt.cleanup()
+		}()
+	}
 
 	if url == "" {
-		listen, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", 9222))
+		listen, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opt.port))
 		if err == nil {
 			listen.Close()
 
-			run, err := runner.New()
+			run, err := runner.New(opt.flags...)
 			if err != nil {
+				p.cancel()
 				return nil, err
 			}
 			p.cli = run.Client()
+			p.endpoint = fmt.Sprintf("http://localhost:%d/json", run.Port())
 
 			err = run.Start(p.ctx)
 			if err != nil {
+				p.cancel()
 				return nil, err
 			}
 			cdp, err := chromedp.New(p.ctx,
-				chromedp.WithRunner(run),
+				append(opt.cdpOptions(), chromedp.WithRunner(run))...,
 			)
 			if err != nil {
+				p.cancel()
 				return nil, err
 			}
 			p.cdp = cdp
-			return p, nil
+			return p.init(opt)
 		}
-		url = client.DefaultEndpoint
+		url = fmt.Sprintf("http://localhost:%d/json", opt.port)
 	}
 
 	p.cli = client.New(client.URL(url))
+	p.endpoint = url
 	cdp, err := chromedp.New(p.ctx,
-		//	chromedp.WithLog(log.Printf),
-		chromedp.WithClient(p.ctx, p.cli),
+		append(opt.cdpOptions(), chromedp.WithClient(p.ctx, p.cli))...,
 	)
 	if err != nil {
+		p.cancel()
 		return nil, err
 	}
 	p.cdp = cdp
 
-	return p, nil
+	return p.init(opt)
+}
+
+// init applies the options to the active target.
+func (c *Puppet) init(opt *options) (*Puppet, error) {
+	if c.logger != nil {
+		err := c.logNavigations()
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	for _, action := range opt.actions {
+		err := c.run(c.ctx, action)
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Tab returns a Puppet driving the target with the specified id regardless of the active target,
+// so several targets can be driven concurrently. It has the settings of the Puppet, including
+// the navigation policy, and closing it closes the target only.
+func (c *Puppet) Tab(id string) *Puppet {
+	ctx, cancel := context.WithCancel(c.ctx)
+	tab := c.clone(ctx, cancel, id)
+	if p := tab.navPolicy.get(); p != nil {
+		err := tab.hookNavigationPolicy()
+		if err != nil && c.logger != nil {
+			c.logger.Log(LogError, "navigation policy", "target", id, "error", err)
+		}
+	}
+	return tab
+}
+
+// WithContext returns a Puppet bound to the same target with the same settings whose actions carry the values of ctx,
+// e.g. the span of the caller traced by WithTracer. The actions are still canceled with the Puppet only,
+// and closing it neither closes the target nor the browser.
+func (c *Puppet) WithContext(ctx context.Context) *Puppet {
+	n := c.clone(valueContext{Context: c.ctx, values: ctx}, func() {}, c.target)
+	n.view = true
+	return n
+}
+
+// valueContext is a context with the cancellation of its Context and the values of values, looked up first.
+type valueContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value returns the value of the key in values, or else in the Context.
+func (v valueContext) Value(key interface{}) interface{} {
+	if val := v.values.Value(key); val != nil {
+		return val
+	}
+	return v.Context.Value(key)
+}
+
+// clone returns a Puppet with the settings of the Puppet bound to the target, with the context and its cancel.
+// The overrides are kept by target, and the navigation policy is shared only with a Puppet bound to the same target,
+// the hooks of the Puppet, e.g. the interceptors and the collected events, are not carried over.
+func (c *Puppet) clone(ctx context.Context, cancel func(), target string) *Puppet {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := &Puppet{
+		cdp:    c.cdp,
+		cli:    c.cli,
+		ctx:    ctx,
+		cancel: cancel,
+		target: target,
+		retry:  c.retry,
+		logger: c.logger,
+		tracer: c.tracer,
+
+		endpoint: c.endpoint,
+
+		selectors:      c.selectors,
+		secrets:        c.secrets,
+		failureStore:   c.failureStore,
+		clickStability: c.clickStability,
+		healing:        c.healing,
+		maxTargets:     c.maxTargets,
+	}
+	if c.actionability != nil {
+		a := *c.actionability
+		n.actionability = &a
+	}
+	if c.fingerprints != nil {
+		n.fingerprints = make(map[string]*nodeFingerprint, len(c.fingerprints))
+		for sel, fp := range c.fingerprints {
+			n.fingerprints[sel] = fp
+		}
+	}
+	if c.navPolicy == nil {
+		c.navPolicy = &navigationGuard{}
+	}
+	if c.overrides == nil {
+		c.overrides = &overrideStore{}
+	}
+	n.overrides = c.overrides
+	if c.sessions == nil {
+		c.sessions = &sessionStore{ctx: c.ctx}
+	}
+	n.sessions = c.sessions
+	if target == c.target {
+		n.navPolicy = c.navPolicy
+	} else {
+		n.navPolicy = &navigationGuard{policy: c.navPolicy.get()}
+	}
+	return n
+}
+
+// run runs the action against the target the Puppet is bound to by Tab, or else against the active target.
+func (c *Puppet) run(ctx context.Context, action chromedp.Action) (err error) {
+	var keyvals []interface{}
+	if c.logger != nil || c.tracer != nil {
+		keyvals = []interface{}{"action", actionName(), "target", c.target}
+		if attrs, ok := ctx.Value(logAttrsKey{}).([]interface{}); ok {
+			keyvals = append(keyvals, attrs...)
+		}
+	}
+	if c.logger != nil {
+		c.logger.Log(LogDebug, "action start", keyvals...)
+		start := time.Now()
+		defer func() {
+			keyvals = append(keyvals, "duration", time.Since(start))
+			if err != nil {
+				c.logger.Log(LogError, "action end", append(keyvals, "error", err)...)
+			} else {
+				c.logger.Log(LogDebug, "action end", keyvals...)
+			}
+		}()
+	}
+	if c.tracer != nil {
+		var end func(err error)
+		ctx, end = c.tracer.StartAction(ctx, keyvals...)
+		defer func() {
+			end(err)
+		}()
+	}
+	if c.retry != nil {
+		err = Retry(func() error {
+			return wrapError(c.runOnce(ctx, action))
+		}, *c.retry)
+	} else {
+		err = wrapError(c.runOnce(ctx, action))
+	}
+	if err != nil {
+		err = c.captureFailure(actionName(), err)
+	}
+	return err
+}
+
+func (c *Puppet) runOnce(ctx context.Context, action chromedp.Action) error {
+	if c.target == "" {
+		return c.cdp.Run(ctx, action)
+	}
+	h, err := c.handler(c.target)
+	if err != nil {
+		return err
+	}
+	return action.Do(ctx, h)
 }
 
 // Close closes all Puppet page handlers.
 func (c *Puppet) Close() error {
+	if c.view {
+		return nil
+	}
+	if c.target != "" {
+		defer c.cancel()
+		return c.run(c.ctx,
+			closeTarget(c.target))
+	}
 	c.cancel()
 	// shutdown chrome
 	err := c.cdp.Shutdown(c.ctx)
@@ -89,10 +338,15 @@ func (c *Puppet) Close() error {
 
 // NewTarget an action that creates a new Chrome target, and sets it as the active target.
 func (c *Puppet) NewTarget(url string) (id string, err error) {
+	err = c.checkMaxTargets()
+	if err != nil {
+		return "", err
+	}
 	t, err := c.cli.NewPageTargetWithURL(c.ctx, url)
 	if err != nil {
 		return "", err
 	}
+	c.seeTarget(t.GetID())
 	return t.GetID(), nil
 }
 
@@ -102,28 +356,70 @@ func (c *Puppet) CloseTarget(id string) (err error) {
 		c.cdp.CloseByID(id))
 }
 
+// closeTarget is an action that closes the target with the specified id.
+func closeTarget(id string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		ok, err := target.CloseTarget(target.ID(id)).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("target %q not closed", id)
+		}
+		return nil
+	})
+}
+
 // SetTarget is an action that sets the active Chrome handler to the handler associated with the specified id.
 func (c *Puppet) SetTarget(id string) (err error) {
 	return c.cdp.Run(c.ctx,
 		c.cdp.SetTargetByID(id))
 }
 
-// Targets returns the target IDs of the managed targets.
-func (c *Puppet) Targets() (tabs []string, err error) {
-	return c.cdp.ListTargets(), nil
+// Targets returns the information of all targets, including iframes and workers.
+func (c *Puppet) Targets() (targets []TargetInfo, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		infos, err := target.GetTargets().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		for _, info := range infos {
+			targets = append(targets, newTargetInfo(info))
+		}
+		return nil
+	}))
+	return targets, err
 }
 
 // Navigate navigates the current frame.
 func (c *Puppet) Navigate(url string) error {
-	return c.cdp.Run(c.ctx, chromedp.Tasks{
-		chromedp.Navigate(url),
+	return c.run(c.logAttrs("url", url), chromedp.Tasks{
+		c.navigate(url),
 		waitComplete,
 	})
 }
 
+// NavigateNoCache navigates the current frame, bypassing the browser cache for all requests of the navigation.
+func (c *Puppet) NavigateNoCache(url string) (err error) {
+	err = c.run(c.logAttrs("url", url), chromedp.Tasks{
+		c.withNetwork(network.SetCacheDisabled(true)),
+		c.navigate(url),
+		waitComplete,
+	})
+	// restore the cache even if the navigation failed
+	errRestore := c.run(c.ctx,
+		c.withNetwork(network.SetCacheDisabled(false)))
+	if err != nil {
+		return err
+	}
+	return errRestore
+}
+
 // NavigateBack navigates the current frame backwards in its history.
 func (c *Puppet) NavigateBack() error {
-	return c.cdp.Run(c.ctx, chromedp.Tasks{
+	return c.run(c.ctx, chromedp.Tasks{
 		chromedp.NavigateBack(),
 		waitComplete,
 	})
@@ -131,7 +427,7 @@ func (c *Puppet) NavigateBack() error {
 
 // NavigateForward navigates the current frame forwards in its history.
 func (c *Puppet) NavigateForward() error {
-	return c.cdp.Run(c.ctx, chromedp.Tasks{
+	return c.run(c.ctx, chromedp.Tasks{
 		chromedp.NavigateForward(),
 		waitComplete,
 	})
@@ -139,7 +435,7 @@ func (c *Puppet) NavigateForward() error {
 
 // Reload reloads the current page.
 func (c *Puppet) Reload() error {
-	return c.cdp.Run(c.ctx, chromedp.Tasks{
+	return c.run(c.ctx, chromedp.Tasks{
 		chromedp.Reload(),
 		waitComplete,
 	})
@@ -147,81 +443,93 @@ func (c *Puppet) Reload() error {
 
 // Stop stops all navigation and pending resource retrieval.
 func (c *Puppet) Stop() error {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.ctx,
 		chromedp.Stop(),
 	)
 }
 
 // WaitReady waits until the element is ready (ie, loaded by chromedp).
 func (c *Puppet) WaitReady(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitReady(sel))
 }
 
 // WaitVisible waits until the selected element is visible.
 func (c *Puppet) WaitVisible(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitVisible(sel))
 }
 
 // WaitNotVisible waits until the selected element is not visible.
 func (c *Puppet) WaitNotVisible(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitNotVisible(sel))
 }
 
 // WaitEnabled waits until the selected element is enabled (does not have attribute 'disabled').
 func (c *Puppet) WaitEnabled(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitEnabled(sel))
 }
 
 // WaitSelected waits until the element is selected (has attribute 'selected').
 func (c *Puppet) WaitSelected(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitSelected(sel))
 }
 
 // WaitNotPresent waits until no elements match the specified selector.
 func (c *Puppet) WaitNotPresent(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.WaitNotPresent(sel))
 }
 
 // Evaluate is an action to evaluate the Javascript expression, unmarshaling the result of the script evaluation to res.
 func (c *Puppet) Evaluate(expression string, res interface{}) (err error) {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.logAttrs("expression", expression),
 		chromedp.Evaluate(expression, res))
 }
 
 // Location retrieves the document location.
 func (c *Puppet) Location() (url string, err error) {
-	return url, c.cdp.Run(c.ctx,
+	return url, c.run(c.ctx,
 		chromedp.Location(&url))
 }
 
 // Title retrieves the document title.
 func (c *Puppet) Title() (title string, err error) {
-	return title, c.cdp.Run(c.ctx,
+	return title, c.run(c.ctx,
 		chromedp.Title(&title))
 }
 
 // Click sends a mouse click event to the first node matching the selector.
 func (c *Puppet) Click(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.Click(sel, chromedp.NodeVisible))
+	sel = c.selector(sel)
+	return c.run(c.logAttrs("selector", sel), chromedp.Tasks{
+		c.beforeAction(sel, true),
+		chromedp.Click(sel, chromedp.NodeVisible),
+	})
 }
 
 // DoubleClick sends a mouse double click event to the first node matching the selector.
 func (c *Puppet) DoubleClick(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.DoubleClick(sel, chromedp.NodeVisible))
+	sel = c.selector(sel)
+	return c.run(c.logAttrs("selector", sel), chromedp.Tasks{
+		c.beforeAction(sel, true),
+		chromedp.DoubleClick(sel, chromedp.NodeVisible),
+	})
 }
 
 // OuterHTML retrieves the outer html of the first node matching the selector.
 func (c *Puppet) OuterHTML() (res []byte, err error) {
 	var src string
-	err = c.cdp.Run(c.ctx,
+	err = c.run(c.ctx,
 		chromedp.OuterHTML("html", &src, chromedp.ByQuery),
 	)
 	if err != nil {
@@ -233,7 +541,7 @@ func (c *Puppet) OuterHTML() (res []byte, err error) {
 // InnerHTML retrieves the inner html of the first node matching the selector.
 func (c *Puppet) InnerHTML() (res []byte, err error) {
 	var src string
-	err = c.cdp.Run(c.ctx,
+	err = c.run(c.ctx,
 		chromedp.InnerHTML("html", &src, chromedp.ByQuery),
 	)
 	if err != nil {
@@ -242,119 +550,184 @@ func (c *Puppet) InnerHTML() (res []byte, err error) {
 	return *(*[]byte)(unsafe.Pointer(&src)), nil
 }
 
-// SetValue sets the value of an element.
+// SetValue sets the value of an element, the element may live inside an iframe.
 func (c *Puppet) SetValue(sel string, value string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.SetValue(sel, value))
+	sel = c.selector(sel)
+	value, err = c.expandSecrets(value)
+	if err != nil {
+		return err
+	}
+	return c.run(c.logAttrs("selector", sel), chromedp.Tasks{
+		c.beforeAction(sel, false),
+		inFrames(sel, chromedp.SetValue(sel, value), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+			return callOnNode(ctx, h, id, `function (value) {
+	this.value = value;
+	this.dispatchEvent(new Event("input", { bubbles: true }));
+	this.dispatchEvent(new Event("change", { bubbles: true }));
+}`, nil, value)
+		}),
+	})
 }
 
-// Value retrieves the value of the first node matching the selector.
+// Value retrieves the value of the first node matching the selector, the node may live inside an iframe.
 func (c *Puppet) Value(sel string) (value string, err error) {
-	return value, c.cdp.Run(c.ctx,
-		chromedp.Value(sel, &value))
+	sel = c.selector(sel)
+	return value, c.run(c.ctx,
+		inFrames(sel, chromedp.Value(sel, &value), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+			return callOnNode(ctx, h, id, `function () { return this.value }`, &value)
+		}))
 }
 
 // Text retrieves the visible text of the first node matching the selector.
 func (c *Puppet) Text(sel string) (value string, err error) {
-	return value, c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return value, c.run(c.ctx,
 		chromedp.Text(sel, &value))
 }
 
 // Clear clears the values of any input/textarea nodes matching the selector.
 func (c *Puppet) Clear(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.Clear(sel))
 }
 
 // Focus focuses the first node matching the selector.
 func (c *Puppet) Focus(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.Focus(sel))
 }
 
 // KeyAction will synthesize a keyDown, char, and keyUp event for each rune contained in keys along with any supplied key options.
 func (c *Puppet) KeyAction(key string) (err error) {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.ctx,
 		chromedp.KeyAction(key))
 }
 
 // SetAttributes sets the element attributes for the first node matching the selector.
 func (c *Puppet) SetAttributes(sel string, value map[string]string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.SetAttributes(sel, value))
 }
 
 // Attributes retrieves the element attributes for the first node matching the selector.
 func (c *Puppet) Attributes(sel string) (value map[string]string, err error) {
-	return value, c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return value, c.run(c.ctx,
 		chromedp.Attributes(sel, &value))
 }
 
 // AttributesAll retrieves the element attributes for all nodes matching the selector.
 func (c *Puppet) AttributesAll(sel string) (value []map[string]string, err error) {
-	return value, c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return value, c.run(c.ctx,
 		chromedp.AttributesAll(sel, &value))
 }
 
 // SetAttributeValue sets the element attribute with name to value for the first node matching the selector.
 func (c *Puppet) SetAttributeValue(sel string, name, value string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.SetAttributeValue(sel, name, value))
 }
 
 // AttributeValue retrieves the element attribute value for the first node matching the selector.
 func (c *Puppet) AttributeValue(sel string, name string) (value string, ok bool, err error) {
-	return value, ok, c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return value, ok, c.run(c.ctx,
 		chromedp.AttributeValue(sel, name, &value, &ok))
 }
 
 // DelAttribute removes the element attribute with name from the first node matching the selector.
 func (c *Puppet) DelAttribute(sel string, name string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.RemoveAttribute(sel, name))
 }
 
 // SendKeys synthesizes the key up, char, and down events as needed for the runes in v, sending them to the first node matching the selector.
 func (c *Puppet) SendKeys(sel string, v string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.SendKeys(sel, v))
+	sel = c.selector(sel)
+	v, err = c.expandSecrets(v)
+	if err != nil {
+		return err
+	}
+	return c.run(c.logAttrs("selector", sel), chromedp.Tasks{
+		c.beforeAction(sel, false),
+		chromedp.SendKeys(sel, v),
+	})
 }
 
-// Submit is an action that submits the form of the first node matching the selector belongs to.
+// Submit is an action that submits the form of the first node matching the selector belongs to,
+// the node may live inside an iframe.
 func (c *Puppet) Submit(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.Submit(sel))
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		inFrames(sel, chromedp.Submit(sel), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+			return callOnNode(ctx, h, id, `function () {
+	var form = this.nodeName === "FORM" ? this : this.form;
+	if (!form) {
+		throw new Error("node is not in a form");
+	}
+	form.submit();
+}`, nil)
+		}))
 }
 
-// SetUploadFiles sets the files to upload (ie, for a input[type="file"] node) for the first node matching the selector.
+// SetUploadFiles sets the files to upload (ie, for a input[type="file"] node) for the first node matching the selector,
+// the node may live inside an iframe.
 func (c *Puppet) SetUploadFiles(sel string, files []string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.SetUploadFiles(sel, files))
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		inFrames(sel, chromedp.SetUploadFiles(sel, files), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+			return dom.SetFileInputFiles(files).
+				WithNodeID(id).
+				Do(ctx, h)
+		}))
 }
 
-// Reset is an action that resets the form of the first node matching the selector belongs to.
+// Reset is an action that resets the form of the first node matching the selector belongs to,
+// the node may live inside an iframe.
 func (c *Puppet) Reset(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
-		chromedp.Reset(sel))
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		inFrames(sel, chromedp.Reset(sel), func(ctx context.Context, h cdp.Executor, id cdp.NodeID) error {
+			return callOnNode(ctx, h, id, `function () {
+	var form = this.nodeName === "FORM" ? this : this.form;
+	if (!form) {
+		throw new Error("node is not in a form");
+	}
+	form.reset();
+}`, nil)
+		}))
 }
 
 // ScrollIntoView scrolls the window to the first node matching the selector.
 func (c *Puppet) ScrollIntoView(sel string) (err error) {
-	return c.cdp.Run(c.ctx,
+	sel = c.selector(sel)
+	return c.run(c.ctx,
 		chromedp.ScrollIntoView(sel))
 }
 
 // SetHeaders specifies whether to always send extra HTTP headers with the requests from this page.
 func (c *Puppet) SetHeaders(headers map[string]interface{}) (err error) {
-	return c.cdp.Run(c.ctx,
-		network.SetExtraHTTPHeaders(network.Headers(headers)))
+	err = c.run(c.ctx,
+		c.withNetwork(network.SetExtraHTTPHeaders(network.Headers(headers))))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.Headers = headers
+	})
+	return nil
 }
 
 // SetCookies sets given cookies.
 func (c *Puppet) SetCookies(cookies []*http.Cookie) (err error) {
 	cookieParams := []*network.CookieParam{}
 	for _, cookie := range cookies {
-		expr := cdp.TimeSinceEpoch(cookie.Expires)
 		var cookieSameSite network.CookieSameSite
 		switch cookie.SameSite {
 		case http.SameSiteDefaultMode:
@@ -363,7 +736,7 @@ func (c *Puppet) SetCookies(cookies []*http.Cookie) (err error) {
 		case http.SameSiteStrictMode:
 			cookieSameSite = network.CookieSameSiteStrict
 		}
-		cookieParams = append(cookieParams, &network.CookieParam{
+		param := &network.CookieParam{
 			Name:     cookie.Name,
 			Value:    cookie.Value,
 			Domain:   cookie.Domain,
@@ -371,11 +744,16 @@ func (c *Puppet) SetCookies(cookies []*http.Cookie) (err error) {
 			Secure:   cookie.Secure,
 			HTTPOnly: cookie.HttpOnly,
 			SameSite: cookieSameSite,
-			Expires:  &expr,
-		})
+		}
+		// a cookie without expiry is a session cookie
+		if !cookie.Expires.IsZero() {
+			expr := cdp.TimeSinceEpoch(cookie.Expires)
+			param.Expires = &expr
+		}
+		cookieParams = append(cookieParams, param)
 	}
 
-	err = c.cdp.Run(c.ctx,
+	err = c.run(c.ctx,
 		network.SetCookies(cookieParams))
 	if err != nil {
 		return err
@@ -385,19 +763,19 @@ func (c *Puppet) SetCookies(cookies []*http.Cookie) (err error) {
 
 // DelCookies deletes browser cookies with matching name and url or domain/path pair.
 func (c *Puppet) DelCookies(name string) (err error) {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.ctx,
 		network.DeleteCookies(name))
 }
 
 // ClearCookies clears browser cookies.
 func (c *Puppet) ClearCookies() (err error) {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.ctx,
 		network.ClearBrowserCookies())
 }
 
 // Cookies returns all browser cookies. Depending on the backend support, will return detailed cookie information in the cookies field.
 func (c *Puppet) Cookies() (cookies []*http.Cookie, err error) {
-	err = c.cdp.Run(c.ctx, chromedp.ActionFunc(func(ctxt context.Context, h cdp.Executor) error {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctxt context.Context, h cdp.Executor) error {
 		cookieResults, err := network.GetAllCookies().
 			Do(ctxt, h)
 		if err != nil {
@@ -411,7 +789,7 @@ func (c *Puppet) Cookies() (cookies []*http.Cookie, err error) {
 			case network.CookieSameSiteStrict:
 				cookieSameSite = http.SameSiteStrictMode
 			}
-			cookies = append(cookies, &http.Cookie{
+			c := &http.Cookie{
 				Name:     cookie.Name,
 				Value:    cookie.Value,
 				Domain:   cookie.Domain,
@@ -419,8 +797,12 @@ func (c *Puppet) Cookies() (cookies []*http.Cookie, err error) {
 				Secure:   cookie.Secure,
 				HttpOnly: cookie.HTTPOnly,
 				SameSite: cookieSameSite,
-				Expires:  time.Date(1970, 1, 1, 0, 0, int(cookie.Expires), 0, time.UTC).Local(),
-			})
+			}
+			// session cookies are left without expiry
+			if !cookie.Session {
+				c.Expires = time.Date(1970, 1, 1, 0, 0, int(cookie.Expires), 0, time.UTC).Local()
+			}
+			cookies = append(cookies, c)
 		}
 		return nil
 	}))
@@ -429,7 +811,7 @@ func (c *Puppet) Cookies() (cookies []*http.Cookie, err error) {
 
 // PDF print page as PDF.
 func (c *Puppet) PDF() (res []byte, err error) {
-	err = c.cdp.Run(c.ctx, chromedp.ActionFunc(func(ctxt context.Context, h cdp.Executor) error {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctxt context.Context, h cdp.Executor) error {
 		res, err = page.PrintToPDF().
 			WithMarginTop(0.01).
 			WithMarginBottom(0.01).
@@ -450,7 +832,7 @@ func (c *Puppet) PDF() (res []byte, err error) {
 
 // Screenshot capture page screenshot.
 func (c *Puppet) Screenshot() (res []byte, err error) {
-	err = c.cdp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
 		res, err = page.CaptureScreenshot().
 			Do(ctx, h)
 		return err
@@ -468,7 +850,7 @@ func (c *Puppet) Screenshot() (res []byte, err error) {
 // and element-inline styles.
 func (c *Puppet) Snapshot() (res []byte, err error) {
 	var src string
-	err = c.cdp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
 		src, err = page.CaptureSnapshot().
 			Do(ctx, h)
 		return err
@@ -482,7 +864,7 @@ func (c *Puppet) Snapshot() (res []byte, err error) {
 
 // ClearCache clears browser cache.
 func (c *Puppet) ClearCache() (err error) {
-	return c.cdp.Run(c.ctx,
+	return c.run(c.ctx,
 		network.ClearBrowserCache())
 }
 
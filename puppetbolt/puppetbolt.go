@@ -0,0 +1,168 @@
+// Package puppetbolt stores the frontier and the results of puppet crawls in a BoltDB file,
+// so interrupted crawls can resume where they left off.
+package puppetbolt
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/wzshiming/puppet"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	queueBucket   = []byte("queue")
+	pendingBucket = []byte("pending")
+	seenBucket    = []byte("seen")
+	resultsBucket = []byte("results")
+)
+
+// Store is a puppet.CrawlStore in a BoltDB file.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ puppet.CrawlStore = (*Store)(nil)
+
+// Open opens or creates the store in the file at the path, enqueuing again the items
+// pending when the store was closed.
+func Open(path string, mode os.FileMode) (*Store, error) {
+	db, err := bolt.Open(path, mode, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{queueBucket, pendingBucket, seenBucket, resultsBucket} {
+			_, err := tx.CreateBucketIfNotExists(name)
+			if err != nil {
+				return err
+			}
+		}
+		queue := tx.Bucket(queueBucket)
+		pending := tx.Bucket(pendingBucket)
+		var keys [][]byte
+		err := pending.ForEach(func(k, v []byte) error {
+			keys = append(keys, k)
+			return push(queue, v)
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			err = pending.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the store.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Enqueue adds the item to the frontier unless its key has been seen.
+func (s *Store) Enqueue(item puppet.CrawlItem) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		seen := tx.Bucket(seenBucket)
+		if seen.Get([]byte(item.Key)) != nil {
+			return nil
+		}
+		err := seen.Put([]byte(item.Key), []byte{})
+		if err != nil {
+			return err
+		}
+		return push(tx.Bucket(queueBucket), data)
+	})
+}
+
+// Dequeue removes the next item from the frontier, the item is pending until its result is saved.
+func (s *Store) Dequeue() (item puppet.CrawlItem, ok bool, err error) {
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		queue := tx.Bucket(queueBucket)
+		k, v := queue.Cursor().First()
+		if k == nil {
+			return nil
+		}
+		err := json.Unmarshal(v, &item)
+		if err != nil {
+			return err
+		}
+		err = tx.Bucket(pendingBucket).Put([]byte(item.Key), v)
+		if err != nil {
+			return err
+		}
+		ok = true
+		return queue.Delete(k)
+	})
+	if err != nil {
+		return puppet.CrawlItem{}, false, err
+	}
+	return item, ok, nil
+}
+
+// Seen reports whether an item with the key has been enqueued.
+func (s *Store) Seen(key string) (seen bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		seen = tx.Bucket(seenBucket).Get([]byte(key)) != nil
+		return nil
+	})
+	return seen, err
+}
+
+// SaveResult saves the result of the visit of an item, which is no longer pending.
+func (s *Store) SaveResult(result puppet.CrawlResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		err := tx.Bucket(pendingBucket).Delete([]byte(result.Key))
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(resultsBucket).Put([]byte(result.Key), data)
+	})
+}
+
+// Results calls fn with every result saved, in the order of their urls, until fn returns false.
+func (s *Store) Results(fn func(puppet.CrawlResult) bool) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(resultsBucket).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			var result puppet.CrawlResult
+			err := json.Unmarshal(v, &result)
+			if err != nil {
+				return err
+			}
+			if !fn(result) {
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// push appends the value to the queue bucket, keyed by a big endian sequence to keep the order.
+func push(queue *bolt.Bucket, v []byte) error {
+	seq, err := queue.NextSequence()
+	if err != nil {
+		return err
+	}
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return queue.Put(k, v)
+}
@@ -0,0 +1,170 @@
+// Package puppetmetrics exposes Prometheus metrics of browser automation with puppet.
+package puppetmetrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/wzshiming/puppet"
+)
+
+// Metrics collects the metrics of the Puppets it is the Logger of.
+type Metrics struct {
+	actions    *prometheus.CounterVec
+	failures   *prometheus.CounterVec
+	durations  *prometheus.HistogramVec
+	navigation prometheus.Histogram
+	targets    prometheus.Gauge
+	restarts   prometheus.Counter
+
+	next puppet.Logger
+}
+
+// New creates the metrics with the namespace, e.g. "scraper", and forwards the log messages to next if not nil.
+func New(namespace string, next puppet.Logger) *Metrics {
+	return &Metrics{
+		actions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "actions_total",
+			Help:      "Number of actions executed.",
+		}, []string{"action"}),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "action_failures_total",
+			Help:      "Number of actions failed by the type of the failure.",
+		}, []string{"action", "type"}),
+		durations: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "action_duration_seconds",
+			Help:      "Duration of the actions.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+		}, []string{"action"}),
+		navigation: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "navigation_duration_seconds",
+			Help:      "Duration of the navigations until the page has loaded.",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 12),
+		}),
+		targets: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "open_targets",
+			Help:      "Number of open page targets.",
+		}),
+		restarts: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "puppet",
+			Name:      "browser_restarts_total",
+			Help:      "Number of browser restarts.",
+		}),
+		next: next,
+	}
+}
+
+// Register registers the metrics on the registry, e.g. prometheus.DefaultRegisterer.
+func (m *Metrics) Register(r prometheus.Registerer) (err error) {
+	for _, c := range []prometheus.Collector{m.actions, m.failures, m.durations, m.navigation, m.targets, m.restarts} {
+		err = r.Register(c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Log implements puppet.Logger, pass the Metrics to puppet.WithLogger to collect the metrics of a Puppet.
+func (m *Metrics) Log(level puppet.LogLevel, msg string, keyvals ...interface{}) {
+	if msg == "action end" {
+		m.observe(keyvals)
+	}
+	if m.next != nil {
+		m.next.Log(level, msg, keyvals...)
+	}
+}
+
+func (m *Metrics) observe(keyvals []interface{}) {
+	var action string
+	var duration time.Duration
+	var err error
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case "action":
+			action, _ = keyvals[i+1].(string)
+		case "duration":
+			duration, _ = keyvals[i+1].(time.Duration)
+		case "error":
+			err, _ = keyvals[i+1].(error)
+		}
+	}
+	if action == "" {
+		return
+	}
+	m.actions.WithLabelValues(action).Inc()
+	m.durations.WithLabelValues(action).Observe(duration.Seconds())
+	if err != nil {
+		m.failures.WithLabelValues(action, failureType(err)).Inc()
+		return
+	}
+	if strings.HasPrefix(action, "Navigate") || action == "Reload" {
+		m.navigation.Observe(duration.Seconds())
+	}
+}
+
+// failureType classifies the error of a failed action by the errors of puppet it matches.
+func failureType(err error) string {
+	switch {
+	case errors.Is(err, puppet.ErrTimeout) || errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, puppet.ErrNavigationFailed):
+		return "navigation"
+	case errors.Is(err, puppet.ErrNodeNotFound):
+		return "not_found"
+	case errors.Is(err, puppet.ErrDetached):
+		return "detached"
+	case errors.Is(err, puppet.ErrTargetCrashed):
+		return "crashed"
+	case errors.Is(err, puppet.ErrTargetNotFound):
+		return "target_not_found"
+	case puppet.IsTransient(err):
+		return "transient"
+	}
+	return "other"
+}
+
+// WatchTargets updates the number of open page targets of the browser of the Puppet every interval,
+// until the context is done.
+func (m *Metrics) WatchTargets(ctx context.Context, p *puppet.Puppet, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		targets, err := p.Targets()
+		if err == nil {
+			n := 0
+			for _, t := range targets {
+				if t.Type == "page" {
+					n++
+				}
+			}
+			m.targets.Set(float64(n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BrowserRestarted counts a restart of a browser, to be called by the code relaunching crashed browsers.
+func (m *Metrics) BrowserRestarted() {
+	m.restarts.Inc()
+}
@@ -0,0 +1,191 @@
+// Package puppettest provides helpers for browser tests with puppet.
+package puppettest
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wzshiming/puppet"
+)
+
+// Timeout is how long the expectations wait for the page to meet them.
+var Timeout = 5 * time.Second
+
+var (
+	once   sync.Once
+	shared *puppet.Puppet
+	err    error
+)
+
+// Browser returns the browser shared by all tests of the process,
+// connecting to the DevTools endpoint in the PUPPET_URL environment variable or starting a new browser,
+// which is closed by Close.
+func Browser(t testing.TB) *puppet.Puppet {
+	t.Helper()
+	once.Do(func() {
+		shared, err = start()
+	})
+	if err != nil {
+		t.Fatalf("puppettest: start browser: %v", err)
+	}
+	return shared
+}
+
+// Close closes the browser shared by the tests if it was started, call it from TestMain once the tests ran:
+//
+//	func TestMain(m *testing.M) {
+//		code := m.Run()
+//		puppettest.Close()
+//		os.Exit(code)
+//	}
+func Close() error {
+	if shared == nil {
+		return nil
+	}
+	return shared.Close()
+}
+
+// start launches a browser on a free port, or connects to the DevTools endpoint in the PUPPET_URL environment variable.
+func start(opts ...puppet.Option) (*puppet.Puppet, error) {
+	url := os.Getenv("PUPPET_URL")
+	if url == "" {
+		port, err := freePort()
+		if err != nil {
+			return nil, err
+		}
+		opts = append([]puppet.Option{puppet.WithPort(port)}, opts...)
+	}
+	return puppet.NewPuppet(url, opts...)
+}
+
+// freePort returns a port free on the loopback interface.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Context returns a Puppet driving a new target in a new isolated browser context of the shared browser,
+// the browser context is closed when the test ends. Tests using it can run with t.Parallel
+// without sharing cookies or storage.
+func Context(t testing.TB) *puppet.Puppet {
+	t.Helper()
+	return ContextWith(t, puppet.ContextOptions{})
+}
+
+// ContextWith is like Context, the opts are applied to the browser context.
+func ContextWith(t testing.TB, opts puppet.ContextOptions) *puppet.Puppet {
+	t.Helper()
+	b := Browser(t)
+	contextID, err := b.NewContext(opts)
+	if err != nil {
+		t.Fatalf("puppettest: new browser context: %v", err)
+	}
+	t.Cleanup(func() {
+		err := b.CloseContext(contextID)
+		if err != nil {
+			t.Errorf("puppettest: close browser context: %v", err)
+		}
+	})
+	id, err := b.NewContextTarget(contextID, "about:blank")
+	if err != nil {
+		t.Fatalf("puppettest: new target: %v", err)
+	}
+	return b.Tab(id)
+}
+
+// New launches a browser only used by the test on a free port, or connects to the DevTools endpoint
+// in the PUPPET_URL environment variable, and closes it when the test ends.
+func New(t testing.TB, opts ...puppet.Option) *puppet.Puppet {
+	t.Helper()
+	p, err := start(opts...)
+	if err != nil {
+		t.Fatalf("puppettest: start browser: %v", err)
+	}
+	t.Cleanup(func() {
+		err := p.Close()
+		if err != nil {
+			t.Errorf("puppettest: close browser: %v", err)
+		}
+	})
+	return p
+}
+
+// ExpectVisible fails the test if no node matching the selector becomes visible within the Timeout.
+func ExpectVisible(t testing.TB, p *puppet.Puppet, sel string) {
+	t.Helper()
+	err := poll(func() (bool, error) {
+		visible := false
+		err := p.IfVisible(sel, func() error {
+			visible = true
+			return nil
+		}, nil)
+		return visible, err
+	})
+	if err != nil {
+		t.Fatalf("puppettest: expect %q visible: %v", sel, err)
+	}
+}
+
+// ExpectText fails the test if the text of the first node matching the selector does not become
+// the text within the Timeout, the texts are compared once their whitespace and Unicode forms are normalized.
+func ExpectText(t testing.TB, p *puppet.Puppet, sel string, want string) {
+	t.Helper()
+	got := ""
+	err := poll(func() (ok bool, err error) {
+		err = p.IfVisible(sel, func() error {
+			ok, got, err = p.TextEqual(sel, want, puppet.TextOptions{})
+			return err
+		}, nil)
+		return ok, err
+	})
+	if err != nil {
+		t.Fatalf("puppettest: expect text of %q to be %q, got %q: %v", sel, want, got, err)
+	}
+}
+
+// ExpectURL fails the test if the url of the page does not become the url within the Timeout,
+// a url ending with "*" matches any url with the prefix.
+func ExpectURL(t testing.TB, p *puppet.Puppet, want string) {
+	t.Helper()
+	got := ""
+	err := poll(func() (ok bool, err error) {
+		got, err = p.Location()
+		if err != nil {
+			return false, err
+		}
+		if strings.HasSuffix(want, "*") {
+			return strings.HasPrefix(got, strings.TrimSuffix(want, "*")), nil
+		}
+		return got == want, nil
+	})
+	if err != nil {
+		t.Fatalf("puppettest: expect url %q, got %q: %v", want, got, err)
+	}
+}
+
+// poll calls fn until it reports true or the Timeout passed.
+func poll(fn func() (bool, error)) error {
+	deadline := time.Now().Add(Timeout)
+	for {
+		ok, err := fn()
+		if ok {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("timed out after %v", Timeout)
+		}
+		time.Sleep(time.Second / 10)
+	}
+}
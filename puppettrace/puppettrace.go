@@ -0,0 +1,125 @@
+// Package puppettrace emits OpenTelemetry spans for the actions of puppet.
+package puppettrace
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/wzshiming/puppet"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing emits a span for every action of the Puppets it is the Tracer of,
+// with the selector, url or expression of the action and its error as attributes.
+// The spans are children of the span in the context passed to Puppet.WithContext, if any.
+type Tracing struct {
+	tracer trace.Tracer
+
+	mu     sync.Mutex
+	active map[string]map[*action]struct{}
+
+	next puppet.Logger
+}
+
+// action is an action in progress.
+type action struct {
+	span trace.Span
+}
+
+// New creates the tracing with the tracer provider, e.g. otel.GetTracerProvider(),
+// and forwards the log messages to next if not nil.
+func New(tp trace.TracerProvider, next puppet.Logger) *Tracing {
+	return &Tracing{
+		tracer: tp.Tracer("github.com/wzshiming/puppet"),
+		active: map[string]map[*action]struct{}{},
+		next:   next,
+	}
+}
+
+// StartAction implements puppet.Tracer, pass the Tracing to puppet.WithTracer to trace the actions of a Puppet,
+// e.g. p.WithContext(ctx).Click(sel) emits the span of the click as a child of the span in ctx.
+func (t *Tracing) StartAction(ctx context.Context, keyvals ...interface{}) (context.Context, func(err error)) {
+	name := "puppet"
+	target := ""
+	attrs := []attribute.KeyValue{}
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key, _ := keyvals[i].(string)
+		switch v := keyvals[i+1].(type) {
+		case string:
+			switch key {
+			case "action":
+				if v != "" {
+					name = "puppet." + v
+				}
+			case "target":
+				target = v
+			}
+			if v != "" {
+				attrs = append(attrs, attribute.String("puppet."+key, v))
+			}
+		default:
+			attrs = append(attrs, attribute.String("puppet."+key, fmt.Sprint(v)))
+		}
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	a := &action{span: span}
+	start := time.Now()
+
+	t.mu.Lock()
+	if t.active[target] == nil {
+		t.active[target] = map[*action]struct{}{}
+	}
+	t.active[target][a] = struct{}{}
+	t.mu.Unlock()
+
+	return ctx, func(err error) {
+		t.mu.Lock()
+		delete(t.active[target], a)
+		if len(t.active[target]) == 0 {
+			delete(t.active, target)
+		}
+		t.mu.Unlock()
+
+		span.SetAttributes(attribute.Int64("puppet.duration_ms", time.Since(start).Milliseconds()))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Log implements puppet.Logger, pass the Tracing to puppet.WithLogger as well to add the navigations
+// of a target as events to the spans of the actions in progress on it.
+func (t *Tracing) Log(level puppet.LogLevel, msg string, keyvals ...interface{}) {
+	if msg == "navigated" {
+		t.navigated(keyvals)
+	}
+	if t.next != nil {
+		t.next.Log(level, msg, keyvals...)
+	}
+}
+
+func (t *Tracing) navigated(keyvals []interface{}) {
+	target := ""
+	url := ""
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		switch keyvals[i] {
+		case "target":
+			target, _ = keyvals[i+1].(string)
+		case "url":
+			url, _ = keyvals[i+1].(string)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for a := range t.active[target] {
+		a.span.AddEvent("navigated", trace.WithAttributes(attribute.String("url.full", url)))
+	}
+}
@@ -0,0 +1,127 @@
+package puppet
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/io"
+	"github.com/chromedp/chromedp"
+)
+
+// loadNetworkResourceParams are the parameters of Network.loadNetworkResource, which cdproto lacks.
+type loadNetworkResourceParams struct {
+	FrameID cdp.FrameID                `json:"frameId,omitempty"`
+	URL     string                     `json:"url"`
+	Options loadNetworkResourceOptions `json:"options"`
+}
+
+type loadNetworkResourceOptions struct {
+	DisableCache       bool `json:"disableCache"`
+	IncludeCredentials bool `json:"includeCredentials"`
+}
+
+// loadedResource is the resource loaded by Network.loadNetworkResource.
+type loadedResource struct {
+	Success        bool                   `json:"success"`
+	NetErrorName   string                 `json:"netErrorName"`
+	HTTPStatusCode float64                `json:"httpStatusCode"`
+	Stream         io.StreamHandle        `json:"stream"`
+	Headers        map[string]interface{} `json:"headers"`
+}
+
+// FetchResource downloads the resource at the url through the network stack of the current page,
+// with its cookies and authentication, and returns its body and content type without navigating away,
+// e.g. for a preview of a linked PDF. Unlike a fetch by the page, cross-origin resources are not limited by CORS.
+func (c *Puppet) FetchResource(rawurl string) (data []byte, contentType string, err error) {
+	if strings.HasPrefix(rawurl, "data:") {
+		return readDataURL(rawurl)
+	}
+	err = c.run(c.logAttrs("url", rawurl), chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		var loaded struct {
+			Resource *loadedResource `json:"resource"`
+		}
+		err := execute(ctx, h, "Network.loadNetworkResource", &loadNetworkResourceParams{
+			FrameID: cdp.FrameID(mainFrameID(ctx, h)),
+			URL:     rawurl,
+			Options: loadNetworkResourceOptions{
+				IncludeCredentials: true,
+			},
+		}, &loaded)
+		if err != nil {
+			return err
+		}
+		res := loaded.Resource
+		if res == nil {
+			return fmt.Errorf("fetch %s: no resource loaded", rawurl)
+		}
+		if res.Stream != "" {
+			defer io.Close(res.Stream).Do(ctx, h)
+		}
+		status := int(res.HTTPStatusCode)
+		if !res.Success && res.NetErrorName != "" {
+			return fmt.Errorf("fetch %s: %s", rawurl, res.NetErrorName)
+		}
+		if !res.Success || status/100 != 2 {
+			return fmt.Errorf("fetch %s: status %d", rawurl, status)
+		}
+		for k, v := range res.Headers {
+			if s, ok := v.(string); ok && strings.EqualFold(k, "Content-Type") {
+				contentType = s
+			}
+		}
+		if res.Stream == "" {
+			// an empty body
+			return nil
+		}
+		for {
+			var chunk io.ReadReturns
+			err := h.Execute(ctx, io.CommandRead, io.Read(res.Stream), &chunk)
+			if err != nil {
+				return err
+			}
+			if chunk.Base64encoded {
+				b, err := base64.StdEncoding.DecodeString(chunk.Data)
+				if err != nil {
+					return err
+				}
+				data = append(data, b...)
+			} else {
+				data = append(data, chunk.Data...)
+			}
+			if chunk.EOF {
+				return nil
+			}
+		}
+	}))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, contentType, nil
+}
+
+// readDataURL returns the body and the content type of the data url, the body may be empty, e.g. "data:,".
+func readDataURL(rawurl string) (data []byte, contentType string, err error) {
+	i := strings.IndexByte(rawurl, ',')
+	if i < 0 {
+		return nil, "", fmt.Errorf("malformed data url %q", rawurl)
+	}
+	meta, payload := rawurl[len("data:"):i], rawurl[i+1:]
+	encoded := strings.HasSuffix(meta, ";base64")
+	contentType = strings.TrimSuffix(meta, ";base64")
+	if contentType == "" || strings.HasPrefix(contentType, ";") {
+		contentType = "text/plain" + contentType
+	}
+	if encoded {
+		data, err = base64.StdEncoding.DecodeString(payload)
+		return data, contentType, err
+	}
+	s, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return []byte(s), contentType, nil
+}
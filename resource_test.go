@@ -0,0 +1,36 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestReadDataURL(t *testing.T) {
+	tests := []struct {
+		rawurl      string
+		data        string
+		contentType string
+		err         bool
+	}{
+		{rawurl: "data:,", contentType: "text/plain"},
+		{rawurl: "data:,Hello%2C%20World", data: "Hello, World", contentType: "text/plain"},
+		{rawurl: "data:;charset=utf-8,a", data: "a", contentType: "text/plain;charset=utf-8"},
+		{rawurl: "data:text/html,%3Cb%3E", data: "<b>", contentType: "text/html"},
+		{rawurl: "data:image/png;base64,iVBORw==", data: "\x89PNG", contentType: "image/png"},
+		{rawurl: "data:text/plain;base64,", contentType: "text/plain"},
+		{rawurl: "data:text/plain;base64,!!", err: true},
+		{rawurl: "data:text/plain", err: true},
+	}
+	for _, tt := range tests {
+		data, contentType, err := readDataURL(tt.rawurl)
+		if (err != nil) != tt.err {
+			t.Errorf("readDataURL(%q) error = %v, want error %v", tt.rawurl, err, tt.err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if string(data) != tt.data || contentType != tt.contentType {
+			t.Errorf("readDataURL(%q) = %q, %q, want %q, %q", tt.rawurl, data, contentType, tt.data, tt.contentType)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package puppet
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures the retries of failed actions with exponential backoff.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, 3 if zero.
+	Attempts int
+	// Delay is the delay before the first retry, 100ms if zero.
+	Delay time.Duration
+	// MaxDelay caps the delay between retries, unlimited if zero.
+	MaxDelay time.Duration
+	// Multiplier grows the delay after every retry, 2 if zero.
+	Multiplier float64
+	// Retryable reports whether the error is worth retrying, IsTransient if nil.
+	Retryable func(err error) bool
+}
+
+// Retry calls fn until it succeeds, fails with an error that is not retryable, or the attempts are exhausted,
+// and returns the last error.
+func Retry(fn func() error, p RetryPolicy) (err error) {
+	attempts := p.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	delay := p.Delay
+	if delay <= 0 {
+		delay = time.Second / 10
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsTransient
+	}
+
+	for i := 0; ; i++ {
+		err = fn()
+		if err == nil || i+1 >= attempts || !retryable(err) {
+			return err
+		}
+		time.Sleep(delay)
+		delay = time.Duration(float64(delay) * multiplier)
+		if p.MaxDelay > 0 && delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+// WithRetry returns a Puppet bound to the same target with the same settings whose actions are retried with the policy,
+// e.g. c.WithRetry(RetryPolicy{}).Click(sel). Closing it neither closes the target nor the browser.
+func (c *Puppet) WithRetry(p RetryPolicy) *Puppet {
+	n := c.clone(c.ctx, func() {}, c.target)
+	n.retry = &p
+	n.view = true
+	return n
+}
+
+// transientErrors are the messages of the errors caused by the page changing under an action.
+var transientErrors = []string{
+	"could not find node with given id",
+	"no node with given id found",
+	"node is detached from document",
+	"node does not have a layout object",
+	"cannot find context with specified id",
+	"execution context was destroyed",
+	"cannot find default execution context",
+	"inspected target navigated or closed",
+	"not actionable",
+}
+
+// IsTransient reports whether the error is caused by the page changing under an action,
+// e.g. a detached node or a destroyed execution context, so the action may succeed if retried.
+func IsTransient(err error) bool {
+	if err == nil || err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range transientErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
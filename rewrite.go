@@ -0,0 +1,21 @@
+package puppet
+
+// RewriteResponses calls fn with the body of every response of the current target whose url matches
+// the glob pattern, where * matches any sequence of characters, and passes the body returned to the page instead,
+// e.g. to inject a test hook into a vendor script or to strip an anti-automation script.
+func (c *Puppet) RewriteResponses(pattern string, fn func(r *Request, body []byte) []byte) (err error) {
+	return c.intercept(globRegexp(pattern), true, func(r *Request) {
+		if r.StatusCode/100 == 3 || r.StatusCode == 204 || r.StatusCode == 304 {
+			return
+		}
+		body, err := r.Body()
+		if err != nil {
+			return
+		}
+		header := r.ResponseHeader.Clone()
+		// the body is passed decoded and may change its length
+		header.Del("Content-Encoding")
+		header.Del("Content-Length")
+		r.Fulfill(r.StatusCode, header, fn(r, body))
+	})
+}
@@ -0,0 +1,322 @@
+package puppet
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Politeness honors the robots.txt rules and the crawl delay of the hosts, and limits the rate of the requests
+// per host, it can be shared by several crawls.
+type Politeness struct {
+	// UserAgent is the product token the robots.txt rules are matched against, "puppet" if empty.
+	UserAgent string
+	// Delay is the minimum delay between the requests to a host, a longer crawl delay of the robots.txt takes precedence.
+	Delay time.Duration
+	// MaxDelay caps the crawl delay of the robots.txt, unlimited if zero.
+	MaxDelay time.Duration
+	// IgnoreRobots does not fetch nor honor the robots.txt, only the delay applies.
+	IgnoreRobots bool
+	// Client is the client fetching the robots.txt, http.DefaultClient if nil.
+	Client *http.Client
+
+	mu     sync.Mutex
+	robots map[string]*robotsEntry
+	next   map[string]time.Time
+}
+
+type robotsEntry struct {
+	ready chan struct{}
+	rules *robotsRules
+	err   error
+	// expires is when the rules are fetched again
+	expires time.Time
+}
+
+const (
+	// robotsTTL is how long a robots.txt is cached.
+	robotsTTL = 24 * time.Hour
+	// robotsRetry is how long an unavailable robots.txt disallows everything before it is fetched again.
+	robotsRetry = time.Minute
+)
+
+// robotsRule is an allow or disallow rule of a robots.txt.
+type robotsRule struct {
+	allow   bool
+	pattern *regexp.Regexp
+	length  int
+}
+
+// robotsRules are the rules of a robots.txt for a user agent.
+type robotsRules struct {
+	rules []robotsRule
+	delay time.Duration
+	// all disallows or allows everything, e.g. if the robots.txt is unavailable
+	all *bool
+}
+
+// NewPoliteness returns a Politeness honoring the robots.txt and waiting at least one second between the requests to a host.
+func NewPoliteness() *Politeness {
+	return &Politeness{
+		Delay:    time.Second,
+		MaxDelay: time.Minute,
+	}
+}
+
+// Allowed reports whether the robots.txt of the host of the url allows fetching it.
+func (p *Politeness) Allowed(ctx context.Context, rawurl string) (bool, error) {
+	if p.IgnoreRobots {
+		return true, nil
+	}
+	rules, err := p.rules(ctx, rawurl)
+	if err != nil {
+		return false, err
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return false, err
+	}
+	return rules.allowed(u.RequestURI()), nil
+}
+
+// Wait waits until a request to the host of the url is allowed by the delay of the host, or the context is done.
+func (p *Politeness) Wait(ctx context.Context, rawurl string) error {
+	delay := p.Delay
+	if !p.IgnoreRobots {
+		rules, err := p.rules(ctx, rawurl)
+		if err != nil {
+			return err
+		}
+		d := rules.delay
+		if p.MaxDelay != 0 && d > p.MaxDelay {
+			d = p.MaxDelay
+		}
+		if d > delay {
+			delay = d
+		}
+	}
+
+	host := hostOf(rawurl)
+	now := time.Now()
+	p.mu.Lock()
+	if p.next == nil {
+		p.next = map[string]time.Time{}
+	}
+	at := p.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	p.next[host] = at.Add(delay)
+	p.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rules returns the robots.txt rules of the origin of the url, fetching them once until they expire.
+func (p *Politeness) rules(ctx context.Context, rawurl string) (*robotsRules, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
+	origin := strings.ToLower(u.Scheme + "://" + u.Host)
+
+	for {
+		p.mu.Lock()
+		if p.robots == nil {
+			p.robots = map[string]*robotsEntry{}
+		}
+		e, ok := p.robots[origin]
+		if ok && !e.expires.IsZero() && time.Now().After(e.expires) {
+			ok = false
+		}
+		if !ok {
+			e = &robotsEntry{ready: make(chan struct{})}
+			p.robots[origin] = e
+		}
+		p.mu.Unlock()
+
+		if !ok {
+			rules, ttl, err := p.fetch(ctx, origin+"/robots.txt")
+			p.mu.Lock()
+			if err != nil {
+				// not cached, e.g. the context is canceled
+				delete(p.robots, origin)
+			}
+			e.rules, e.err, e.expires = rules, err, time.Now().Add(ttl)
+			p.mu.Unlock()
+			close(e.ready)
+			return rules, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.ready:
+		}
+		if e.err == nil {
+			return e.rules, nil
+		}
+		// the fetch of another caller failed, fetch again
+	}
+}
+
+// fetch fetches the robots.txt and returns its rules with how long they are cached. It allows everything
+// if the robots.txt does not exist, and disallows everything for a while if it is unavailable.
+// It fails only if the context is done.
+func (p *Politeness) fetch(ctx context.Context, robotsURL string) (rules *robotsRules, ttl time.Duration, err error) {
+	allow, disallow := true, false
+	cli := p.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	req, err := http.NewRequest(http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &robotsRules{all: &disallow}, robotsTTL, nil
+	}
+	req = req.WithContext(ctx)
+	resp, err := cli.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, 0, ctx.Err()
+		}
+		return &robotsRules{all: &disallow}, robotsRetry, nil
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return &robotsRules{all: &disallow}, robotsRetry, nil
+	case resp.StatusCode >= 400:
+		return &robotsRules{all: &allow}, robotsTTL, nil
+	}
+	agent := p.UserAgent
+	if agent == "" {
+		agent = "puppet"
+	}
+	rules = parseRobots(io.LimitReader(resp.Body, 500<<10), agent)
+	if ctx.Err() != nil {
+		// the robots.txt may be read partially
+		return nil, 0, ctx.Err()
+	}
+	return rules, robotsTTL, nil
+}
+
+// parseRobots parses the robots.txt and returns the rules of the most specific group matching the user agent.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	agent = strings.ToLower(agent)
+	type group struct {
+		agents []string
+		lines  [][2]string
+	}
+	var groups []*group
+	var cur *group
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:i]))
+		value := strings.TrimSpace(line[i+1:])
+		if key == "user-agent" {
+			if cur == nil || len(cur.lines) != 0 {
+				cur = &group{}
+				groups = append(groups, cur)
+			}
+			cur.agents = append(cur.agents, strings.ToLower(value))
+			continue
+		}
+		if cur != nil {
+			cur.lines = append(cur.lines, [2]string{key, value})
+		}
+	}
+
+	var best *group
+	bestLen := -1
+	for _, g := range groups {
+		for _, a := range g.agents {
+			n := -1
+			if a == "*" {
+				n = 0
+			} else if a != "" && strings.Contains(agent, a) {
+				n = len(a)
+			}
+			if n > bestLen {
+				best, bestLen = g, n
+			}
+		}
+	}
+
+	rules := &robotsRules{}
+	if best == nil {
+		return rules
+	}
+	for _, line := range best.lines {
+		switch line[0] {
+		case "allow", "disallow":
+			if line[1] != "" {
+				rules.rules = append(rules.rules, robotsRule{
+					allow:   line[0] == "allow",
+					pattern: robotsPattern(line[1]),
+					length:  len(line[1]),
+				})
+			}
+		case "crawl-delay":
+			if d, err := strconv.ParseFloat(line[1], 64); err == nil && d > 0 {
+				rules.delay = time.Duration(d * float64(time.Second))
+			}
+		}
+	}
+	return rules
+}
+
+// robotsPattern compiles the path pattern of a robots.txt rule, where "*" matches any characters
+// and a trailing "$" anchors the end of the path.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed reports whether the rules allow the path, the longest matching rule wins and allow wins ties.
+func (r *robotsRules) allowed(path string) bool {
+	if r.all != nil {
+		return *r.all
+	}
+	allow, disallow := -1, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.allow && rule.length > allow {
+			allow = rule.length
+		} else if !rule.allow && rule.length > disallow {
+			disallow = rule.length
+		}
+	}
+	return allow >= disallow
+}
@@ -0,0 +1,75 @@
+package puppet
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const testRobots = `# robots.txt
+User-agent: *
+Disallow: /private/
+Allow: /private/public
+Crawl-delay: 2
+
+User-agent: Puppet
+User-agent: other
+Disallow: /*.pdf$
+Disallow: /tmp
+Allow: /tmp/ok
+Disallow: /same
+Allow: /same
+Disallow:
+
+User-agent: puppetbot
+Disallow: /
+`
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"Mozilla/5.0", "/", true},
+		{"Mozilla/5.0", "/private/page", false},
+		{"Mozilla/5.0", "/private/public/page", true},
+		{"puppet", "/private/page", true},
+		{"puppet", "/doc.pdf", false},
+		{"puppet", "/dir/doc.pdf", false},
+		{"puppet", "/doc.pdf?download=1", true},
+		{"puppet", "/tmp/file", false},
+		{"puppet", "/tmp/ok", true},
+		{"puppet", "/same", true},
+		{"Mozilla/5.0 (compatible; puppetbot/1.0)", "/", false},
+	}
+	for _, tt := range tests {
+		rules := parseRobots(strings.NewReader(testRobots), tt.agent)
+		if got := rules.allowed(tt.path); got != tt.want {
+			t.Errorf("allowed(%q) for %q = %v, want %v", tt.path, tt.agent, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobotsDelay(t *testing.T) {
+	tests := []struct {
+		agent string
+		want  time.Duration
+	}{
+		{"Mozilla/5.0", 2 * time.Second},
+		{"puppet", 0},
+	}
+	for _, tt := range tests {
+		rules := parseRobots(strings.NewReader(testRobots), tt.agent)
+		if rules.delay != tt.want {
+			t.Errorf("delay for %q = %v, want %v", tt.agent, rules.delay, tt.want)
+		}
+	}
+}
+
+func TestParseRobotsEmpty(t *testing.T) {
+	rules := parseRobots(strings.NewReader(""), "puppet")
+	if !rules.allowed("/any") {
+		t.Errorf("an empty robots.txt disallows /any")
+	}
+}
@@ -0,0 +1,109 @@
+package puppet
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/chromedp/chromedp/runner"
+)
+
+// SandboxOptions hardens the browser launched by the Puppet for rendering untrusted pages on shared infrastructure.
+type SandboxOptions struct {
+	// User is the name of the user the browser runs as, the Puppet has to run as root to drop the privileges.
+	User string
+	// UserNamespace runs the browser in a new user namespace, mapping only the user running it.
+	UserNamespace bool
+	// RequireSeccomp fails the launch if the kernel does not support or has disabled the seccomp-bpf filters the sandbox of the browser relies on.
+	RequireSeccomp bool
+	// ProfileTemplate is a read-only browser profile directory copied to a fresh temporary profile for every launch,
+	// so pages can't persist anything into it. The temporary profile is removed once the Puppet is closed.
+	ProfileTemplate string
+}
+
+// WithSandbox launches the browser with the sandbox options, the options are validated by NewPuppet.
+// The sandbox of the browser itself is always kept enabled.
+func WithSandbox(opts SandboxOptions) Option {
+	return func(o *options) {
+		if o.err != nil {
+			return
+		}
+		if opts.RequireSeccomp {
+			err := checkSeccomp()
+			if err != nil {
+				o.err = fmt.Errorf("sandbox: %v", err)
+				return
+			}
+		}
+		if opts.User != "" || opts.UserNamespace {
+			cmdOpt, err := sandboxCmd(opts)
+			if err != nil {
+				o.err = fmt.Errorf("sandbox: %v", err)
+				return
+			}
+			o.flags = append(o.flags, runner.CmdOpt(cmdOpt))
+		}
+		if opts.ProfileTemplate != "" {
+			dir, err := ioutil.TempDir("", "puppet-profile")
+			if err != nil {
+				o.err = fmt.Errorf("sandbox: %v", err)
+				return
+			}
+			err = copyDir(opts.ProfileTemplate, dir)
+			if err != nil {
+				os.RemoveAll(dir)
+				o.err = fmt.Errorf("sandbox: copy profile template: %v", err)
+				return
+			}
+			if opts.User != "" {
+				// the profile has to be writable by the user the browser runs as
+				err = chownDir(dir, opts.User)
+				if err != nil {
+					os.RemoveAll(dir)
+					o.err = fmt.Errorf("sandbox: %v", err)
+					return
+				}
+			}
+			o.flags = append(o.flags, runner.UserDataDir(dir))
+			o.cleanups = append(o.cleanups, func() {
+				os.RemoveAll(dir)
+			})
+		}
+	}
+}
+
+// copyDir copies the regular files and directories below src to dst.
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0700)
+		case !info.Mode().IsRegular():
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, in)
+		if errClose := out.Close(); err == nil {
+			err = errClose
+		}
+		return err
+	})
+}
@@ -0,0 +1,106 @@
+package puppet
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// sandboxCmd returns the option of the command of the browser running it as the user or in a user namespace.
+func sandboxCmd(opts SandboxOptions) (func(*exec.Cmd) error, error) {
+	attr := &syscall.SysProcAttr{}
+	if opts.User != "" {
+		uid, gid, err := lookupUser(opts.User)
+		if err != nil {
+			return nil, err
+		}
+		attr.Credential = &syscall.Credential{
+			Uid: uid,
+			Gid: gid,
+		}
+	}
+	if opts.UserNamespace {
+		if opts.User != "" {
+			return nil, fmt.Errorf("user and user namespace are exclusive")
+		}
+		if data, err := ioutil.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil && strings.TrimSpace(string(data)) == "0" {
+			return nil, fmt.Errorf("unprivileged user namespaces are disabled by the kernel")
+		}
+		attr.Cloneflags = syscall.CLONE_NEWUSER
+		attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}}
+		attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}}
+	}
+	return func(cmd *exec.Cmd) error {
+		cmd.SysProcAttr = attr
+		return nil
+	}, nil
+}
+
+// lookupUser returns the uid and gid of the user the browser runs as, which must not be root.
+func lookupUser(name string) (uid, gid uint32, err error) {
+	if os.Geteuid() != 0 {
+		return 0, 0, fmt.Errorf("running the browser as %q requires root", name)
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	id, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	group, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	if id == 0 {
+		return 0, 0, fmt.Errorf("user %q is root", name)
+	}
+	return uint32(id), uint32(group), nil
+}
+
+// chownDir makes the user the owner of the directory and of everything below it.
+func chownDir(dir, name string) error {
+	uid, gid, err := lookupUser(name)
+	if err != nil {
+		return err
+	}
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, int(uid), int(gid))
+	})
+}
+
+// checkSeccomp checks the kernel supports seccomp filters and they are enabled, a mode of 0 means they are disabled.
+func checkSeccomp() error {
+	f, err := os.Open("/proc/self/status")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		mode := strings.TrimPrefix(scanner.Text(), "Seccomp:")
+		if mode == scanner.Text() {
+			continue
+		}
+		if strings.TrimSpace(mode) == "0" {
+			return fmt.Errorf("seccomp is disabled")
+		}
+		return nil
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("the kernel does not support seccomp")
+}
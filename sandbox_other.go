@@ -0,0 +1,21 @@
+//go:build !linux
+// +build !linux
+
+package puppet
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+func sandboxCmd(opts SandboxOptions) (func(*exec.Cmd) error, error) {
+	return nil, fmt.Errorf("user and user namespace options are only supported on linux")
+}
+
+func chownDir(dir, name string) error {
+	return fmt.Errorf("user option is only supported on linux")
+}
+
+func checkSeccomp() error {
+	return fmt.Errorf("seccomp is only supported on linux")
+}
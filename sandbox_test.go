@@ -0,0 +1,57 @@
+package puppet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tempDirs returns the names of the temporary directories left behind.
+func tempDirs(t *testing.T, dir string) []string {
+	t.Helper()
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names
+}
+
+func TestSandboxProfileRemovedOnError(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	template := filepath.Join(t.TempDir(), "profile")
+	err := os.MkdirAll(filepath.Join(template, "Default"), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("option", func(t *testing.T) {
+		_, err := NewPuppet("", WithSandbox(SandboxOptions{ProfileTemplate: template}), func(o *options) {
+			o.err = os.ErrInvalid
+		})
+		if err == nil {
+			t.Fatal("expected the error of the option")
+		}
+		if dirs := tempDirs(t, tmp); len(dirs) != 0 {
+			t.Fatalf("temporary profiles left behind: %v", dirs)
+		}
+	})
+
+	t.Run("connect", func(t *testing.T) {
+		b := newFakeBrowser(t)
+		url := b.URL()
+		b.close()
+		_, err := NewPuppet(url, WithSandbox(SandboxOptions{ProfileTemplate: template}))
+		if err == nil {
+			t.Fatal("expected the error of the connection")
+		}
+		eventually(t, func() bool {
+			return len(tempDirs(t, tmp)) == 0
+		})
+	})
+}
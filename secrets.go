@@ -0,0 +1,143 @@
+package puppet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SecretsProvider resolves the secrets referenced by placeholders like "${secret:site1/password}".
+type SecretsProvider interface {
+	// Secret returns the secret at the path, e.g. "site1/password".
+	Secret(path string) (string, error)
+}
+
+// EnvSecrets resolves the secrets from the environment variables, the path uppercased with the characters
+// other than letters and digits replaced by underscores and prefixed by the EnvSecrets,
+// e.g. "site1/password" is the variable "PREFIX_SITE1_PASSWORD" with EnvSecrets("PREFIX_").
+type EnvSecrets string
+
+var nonAlnum = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// Secret returns the value of the environment variable of the path.
+func (e EnvSecrets) Secret(path string) (string, error) {
+	name := string(e) + nonAlnum.ReplaceAllString(strings.ToUpper(path), "_")
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("secret %q: environment variable %s not set", path, name)
+	}
+	return v, nil
+}
+
+// FileSecrets resolves the secrets from the files below the directory, e.g. mounted Kubernetes secrets,
+// "site1/password" is the content of the file "site1/password" without trailing newlines.
+type FileSecrets string
+
+// Secret returns the content of the file of the path.
+func (d FileSecrets) Secret(name string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(string(d), filepath.FromSlash(path.Clean("/"+name))))
+	if err != nil {
+		return "", fmt.Errorf("secret %q: %v", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// VaultSecrets resolves the secrets from the key value secrets engine version 2 of HashiCorp Vault,
+// "site1/password" is the key "password" of the secret "site1".
+type VaultSecrets struct {
+	// Addr is the address of Vault, e.g. "https://vault.example.com:8200".
+	Addr  string
+	Token string
+	// Mount is the path the secrets engine is mounted at, "secret" if empty.
+	Mount string
+	// Client is the HTTP client used, http.DefaultClient if nil.
+	Client *http.Client
+}
+
+// Secret returns the key of the secret of the path.
+func (v *VaultSecrets) Secret(name string) (string, error) {
+	i := strings.LastIndex(name, "/")
+	if i < 0 {
+		return "", fmt.Errorf("secret %q: missing key", name)
+	}
+	mount := v.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(v.Addr, "/")+"/v1/"+mount+"/data/"+name[:i], nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+	cli := v.Client
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secret %q: %s", name, resp.Status)
+	}
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return "", err
+	}
+	value, ok := body.Data.Data[name[i+1:]]
+	if !ok {
+		return "", fmt.Errorf("secret %q: key not found", name)
+	}
+	return fmt.Sprint(value), nil
+}
+
+var secretPlaceholder = regexp.MustCompile(`\$\{secret:([^}]+)\}`)
+
+// ExpandSecrets replaces the placeholders like "${secret:site1/password}" in s with the secrets of the provider.
+func ExpandSecrets(s string, p SecretsProvider) (string, error) {
+	var err error
+	res := secretPlaceholder.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		var v string
+		v, err = p.Secret(secretPlaceholder.FindStringSubmatch(m)[1])
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
+// UseSecrets makes SendKeys and SetValue expand the placeholders like "${secret:site1/password}"
+// in their values with the secrets of the provider, so credentials stay out of the code.
+// The values are expanded right before they are typed and never logged.
+func (c *Puppet) UseSecrets(p SecretsProvider) {
+	c.mu.Lock()
+	c.secrets = p
+	c.mu.Unlock()
+}
+
+// expandSecrets expands the secret placeholders of the value if UseSecrets is set.
+func (c *Puppet) expandSecrets(value string) (string, error) {
+	c.mu.Lock()
+	p := c.secrets
+	c.mu.Unlock()
+	if p == nil || !strings.Contains(value, "${secret:") {
+		return value, nil
+	}
+	return ExpandSecrets(value, p)
+}
@@ -0,0 +1,93 @@
+package puppet
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"net/url"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+)
+
+// SecurityDetails is the TLS connection and certificate information of a response.
+type SecurityDetails struct {
+	URL         string
+	Protocol    string
+	KeyExchange string
+	Cipher      string
+	SubjectName string
+	Issuer      string
+	SANs        []string
+	ValidFrom   time.Time
+	ValidTo     time.Time
+	// Certificates is the certificate chain presented by the server, leaf first.
+	Certificates []*x509.Certificate
+}
+
+// CollectSecurityDetails starts collecting the TLS details of the main resource of every navigation of the current target.
+func (c *Puppet) CollectSecurityDetails() (err error) {
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		resp, ok := ev.(*network.EventResponseReceived)
+		if !ok || resp.Type != network.ResourceTypeDocument || resp.Response == nil {
+			return
+		}
+		// only the main frame document is of interest
+		if resp.FrameID != "" && string(resp.FrameID) != mainFrameID(ctx, h) {
+			return
+		}
+
+		sd := resp.Response.SecurityDetails
+		if sd == nil {
+			c.mu.Lock()
+			c.securityDetails = nil
+			c.mu.Unlock()
+			return
+		}
+		details := &SecurityDetails{
+			URL:         resp.Response.URL,
+			Protocol:    sd.Protocol,
+			KeyExchange: sd.KeyExchange,
+			Cipher:      sd.Cipher,
+			SubjectName: sd.SubjectName,
+			Issuer:      sd.Issuer,
+			SANs:        sd.SanList,
+		}
+		if sd.ValidFrom != nil {
+			details.ValidFrom = sd.ValidFrom.Time()
+		}
+		if sd.ValidTo != nil {
+			details.ValidTo = sd.ValidTo.Time()
+		}
+		if u, err := url.Parse(resp.Response.URL); err == nil {
+			chain, err := network.GetCertificate(u.Scheme+"://"+u.Host).
+				Do(ctx, h)
+			if err == nil {
+				for _, raw := range chain {
+					der, err := base64.StdEncoding.DecodeString(raw)
+					if err != nil {
+						continue
+					}
+					cert, err := x509.ParseCertificate(der)
+					if err != nil {
+						continue
+					}
+					details.Certificates = append(details.Certificates, cert)
+				}
+			}
+		}
+		c.mu.Lock()
+		c.securityDetails = details
+		c.mu.Unlock()
+	}, cdproto.EventNetworkResponseReceived)
+}
+
+// SecurityDetails returns the TLS details of the main resource of the last navigation,
+// nil if it was not loaded over a secure connection.
+func (c *Puppet) SecurityDetails() *SecurityDetails {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.securityDetails
+}
@@ -0,0 +1,22 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestCollectSecurityDetails(t *testing.T) {
+	p, b := newFakePuppet(t)
+	err := p.CollectSecurityDetails()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Network.responseReceived", `{"requestId":"1","loaderId":"L","type":"Document","frameId":"F","response":{"url":"https://example.com/","status":200,"statusText":"OK","headers":{},"mimeType":"text/html","connectionReused":false,"connectionId":1,"encodedDataLength":0,"securityState":"secure","securityDetails":{"protocol":"TLS 1.3","keyExchange":"","cipher":"AES_128_GCM","certificateId":0,"subjectName":"example.com","sanList":["example.com"],"issuer":"Example CA","validFrom":1700000000,"validTo":1800000000,"signedCertificateTimestampList":[],"certificateTransparencyCompliance":"unknown"}}}`)
+	eventually(t, func() bool {
+		return p.SecurityDetails() != nil
+	})
+	sd := p.SecurityDetails()
+	if sd.Protocol != "TLS 1.3" || sd.Issuer != "Example CA" || sd.ValidTo.Unix() != 1800000000 {
+		t.Errorf("got security details %+v", sd)
+	}
+}
@@ -0,0 +1,58 @@
+package puppet
+
+import (
+	"github.com/chromedp/chromedp"
+)
+
+const selectText = `function (start, end) {
+	if (typeof this.setSelectionRange === "function") {
+		this.focus();
+		this.setSelectionRange(start, end);
+		return this.value.substring(start, end);
+	}
+	var range = document.createRange();
+	var walker = document.createTreeWalker(this, NodeFilter.SHOW_TEXT);
+	var offset = 0, started = false, node;
+	while ((node = walker.nextNode())) {
+		var length = node.data.length;
+		if (!started && start <= offset + length) {
+			range.setStart(node, start - offset);
+			started = true;
+		}
+		if (started && end <= offset + length) {
+			range.setEnd(node, end - offset);
+			break;
+		}
+		offset += length;
+	}
+	if (!started) {
+		throw new Error("selection start " + start + " out of range");
+	}
+	if (!node) {
+		range.setEnd(this, this.childNodes.length);
+	}
+	var selection = window.getSelection();
+	selection.removeAllRanges();
+	selection.addRange(range);
+	return selection.toString();
+}`
+
+// SelectText selects the text between the character offsets start and end
+// of the first node matching the selector, and returns the selected text.
+func (c *Puppet) SelectText(sel string, start, end int) (text string, err error) {
+	sel = c.selector(sel)
+	return text, c.run(c.ctx,
+		callOn(sel, selectText, &text, start, end))
+}
+
+// SelectedText retrieves the text currently selected in the document, including inside input and textarea nodes.
+func (c *Puppet) SelectedText() (text string, err error) {
+	return text, c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	var el = document.activeElement;
+	if (el && typeof el.selectionStart === "number" && typeof el.value === "string") {
+		return el.value.substring(el.selectionStart, el.selectionEnd);
+	}
+	return window.getSelection().toString();
+})()`, &text))
+}
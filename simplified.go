@@ -0,0 +1,221 @@
+package puppet
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/accessibility"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// ViewElement is an interactive element of the simplified view of a page.
+type ViewElement struct {
+	// ID is the number of the element in the view.
+	ID   int    `json:"id"`
+	Role string `json:"role"`
+	Name string `json:"name"`
+	// Value is the current value of form fields, never reported for passwords and other credentials.
+	Value string `json:"value,omitempty"`
+	// Node is the handle of the element until the page navigates, to act on it with ClickViewElement
+	// and SendKeysViewElement.
+	Node cdp.BackendNodeID `json:"node"`
+}
+
+// SimplifiedView is the interactive elements of a page.
+type SimplifiedView []ViewElement
+
+// String returns the view one element per line, e.g. `[3] button "Sign in"`.
+func (v SimplifiedView) String() string {
+	var sb strings.Builder
+	for _, e := range v {
+		fmt.Fprintf(&sb, "[%d] %s %q", e.ID, e.Role, e.Name)
+		if e.Value != "" {
+			fmt.Fprintf(&sb, " value=%q", e.Value)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// viewRoles are the roles of the interactive elements of the simplified view.
+var viewRoles = map[string]bool{
+	"button":           true,
+	"checkbox":         true,
+	"combobox":         true,
+	"link":             true,
+	"listbox":          true,
+	"menuitem":         true,
+	"menuitemcheckbox": true,
+	"menuitemradio":    true,
+	"option":           true,
+	"radio":            true,
+	"searchbox":        true,
+	"slider":           true,
+	"spinbutton":       true,
+	"switch":           true,
+	"tab":              true,
+	"textbox":          true,
+	"treeitem":         true,
+}
+
+// credentialAutocomplete are the autocomplete tokens of the fields holding credentials.
+var credentialAutocomplete = map[string]bool{
+	"current-password": true,
+	"new-password":     true,
+	"one-time-code":    true,
+	"cc-number":        true,
+	"cc-csc":           true,
+	"cc-exp":           true,
+	"cc-exp-month":     true,
+	"cc-exp-year":      true,
+}
+
+// SimplifiedView returns the interactive elements of the current page from its accessibility tree,
+// with their roles, names and handles to act on them, a compact representation of the page for agents
+// and voice control. Elements hidden from the accessibility tree, e.g. by aria-hidden, and disabled elements
+// are left out, elements of shadow roots are included.
+func (c *Puppet) SimplifiedView() (view SimplifiedView, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		nodes, err := accessibility.GetFullAXTree().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		view = nil
+		for _, n := range nodes {
+			e, ok, err := viewElement(ctx, h, n)
+			if err != nil {
+				return err
+			}
+			if ok {
+				e.ID = len(view) + 1
+				view = append(view, e)
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return view, nil
+}
+
+// viewElement returns the element of the view for the node, if it is an interactive element.
+func viewElement(ctx context.Context, h cdp.Executor, n *accessibility.Node) (e ViewElement, ok bool, err error) {
+	role := axString(n.Role)
+	if n.Ignored || n.BackendDOMNodeID == 0 || !viewRoles[role] {
+		return e, false, nil
+	}
+	states := map[string]string{}
+	for _, p := range n.Properties {
+		states[p.Name.String()] = axString(p.Value)
+	}
+	if states["disabled"] == "true" || states["hidden"] == "true" {
+		return e, false, nil
+	}
+	e = ViewElement{
+		Role: role,
+		Name: viewText(axString(n.Name)),
+		Node: n.BackendDOMNodeID,
+	}
+	switch role {
+	case "checkbox", "radio", "switch", "menuitemcheckbox", "menuitemradio":
+		if states["checked"] == "true" {
+			e.Value = "checked"
+		}
+		return e, true, nil
+	}
+	value := viewText(axString(n.Value))
+	if value == "" {
+		return e, true, nil
+	}
+	secret, err := isCredential(ctx, h, n.BackendDOMNodeID)
+	if err != nil {
+		return e, false, err
+	}
+	if !secret {
+		e.Value = value
+	}
+	return e, true, nil
+}
+
+// isCredential reports whether the node is a password field or a field autocompleted with credentials.
+func isCredential(ctx context.Context, h cdp.Executor, id cdp.BackendNodeID) (bool, error) {
+	node, err := dom.DescribeNode().
+		WithBackendNodeID(id).
+		Do(ctx, h)
+	if err != nil {
+		return false, err
+	}
+	for i := 0; i+1 < len(node.Attributes); i += 2 {
+		name, value := strings.ToLower(node.Attributes[i]), strings.ToLower(node.Attributes[i+1])
+		switch name {
+		case "type":
+			if value == "password" {
+				return true, nil
+			}
+		case "autocomplete":
+			for _, token := range strings.Fields(value) {
+				if credentialAutocomplete[token] {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// viewText collapses the white space of the text and truncates it to 100 runes.
+func viewText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > 100 {
+		s = string(r[:100])
+	}
+	return s
+}
+
+// ClickViewElement sends a mouse click event to the center of the element of a simplified view.
+func (c *Puppet) ClickViewElement(e ViewElement) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		ids, err := dom.PushNodesByBackendIdsToFrontend([]cdp.BackendNodeID{e.Node}).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		err = scrollIntoViewIfNeeded(ctx, h, ids[0])
+		if err != nil {
+			return err
+		}
+		box, err := dom.GetBoxModel().
+			WithBackendNodeID(e.Node).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		q := box.Content
+		return mouseClick((q[0]+q[2]+q[4]+q[6])/4, (q[1]+q[3]+q[5]+q[7])/4, 1).
+			Do(ctx, h)
+	}))
+}
+
+// SendKeysViewElement focuses the element of a simplified view and types v into it.
+func (c *Puppet) SendKeysViewElement(e ViewElement, v string) (err error) {
+	v, err = c.expandSecrets(v)
+	if err != nil {
+		return err
+	}
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		err := dom.Focus().
+			WithBackendNodeID(e.Node).
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		return input.InsertText(v).
+			Do(ctx, h)
+	}))
+}
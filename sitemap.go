@@ -0,0 +1,160 @@
+package puppet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SitemapURL is an url listed by a sitemap.
+type SitemapURL struct {
+	Loc        string
+	LastMod    time.Time
+	ChangeFreq string
+	Priority   float64
+}
+
+// maxSitemaps is the maximum number of sitemaps read by ReadSitemap, including the ones of the indexes.
+const maxSitemaps = 1000
+
+// maxSitemapSize is the maximum size of an uncompressed sitemap.
+const maxSitemapSize = 50 << 20
+
+// ReadSitemap fetches the sitemap at the url with the client, or http.DefaultClient if nil, and returns its urls.
+// Sitemap indexes are followed, and XML, plain text and gzip compressed sitemaps are supported.
+func ReadSitemap(ctx context.Context, cli *http.Client, sitemapURL string) (urls []SitemapURL, err error) {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	queue := []string{sitemapURL}
+	seen := map[string]bool{sitemapURL: true}
+	for len(queue) != 0 {
+		if len(seen) > maxSitemaps {
+			return urls, fmt.Errorf("sitemap: more than %d sitemaps", maxSitemaps)
+		}
+		loc := queue[0]
+		queue = queue[1:]
+		found, indexed, err := readSitemap(ctx, cli, loc)
+		if err != nil {
+			return urls, err
+		}
+		urls = append(urls, found...)
+		for _, s := range indexed {
+			if !seen[s] {
+				seen[s] = true
+				queue = append(queue, s)
+			}
+		}
+	}
+	return urls, nil
+}
+
+// readSitemap fetches the sitemap, and returns its urls or the sitemaps of the index.
+func readSitemap(ctx context.Context, cli *http.Client, loc string) (urls []SitemapURL, sitemaps []string, err error) {
+	req, err := http.NewRequest(http.MethodGet, loc, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.WithContext(ctx)
+	resp, err := cli.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("sitemap %s: %s", loc, resp.Status)
+	}
+
+	r := bufio.NewReader(resp.Body)
+	if magic, err := r.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sitemap %s: %v", loc, err)
+		}
+		defer gz.Close()
+		r = bufio.NewReader(gz)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxSitemapSize))
+	if err != nil {
+		return nil, nil, fmt.Errorf("sitemap %s: %v", loc, err)
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if !bytes.HasPrefix(trimmed, []byte("<")) {
+		// a plain text sitemap lists an url per line
+		for _, line := range strings.Split(string(trimmed), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" {
+				urls = append(urls, SitemapURL{Loc: resolveSitemapURL(loc, line)})
+			}
+		}
+		return urls, nil, nil
+	}
+
+	var v struct {
+		XMLName xml.Name
+		URLs    []struct {
+			Loc        string  `xml:"loc"`
+			LastMod    string  `xml:"lastmod"`
+			ChangeFreq string  `xml:"changefreq"`
+			Priority   float64 `xml:"priority"`
+		} `xml:"url"`
+		Sitemaps []struct {
+			Loc string `xml:"loc"`
+		} `xml:"sitemap"`
+	}
+	err = xml.Unmarshal(data, &v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sitemap %s: %v", loc, err)
+	}
+	for _, s := range v.Sitemaps {
+		if s := strings.TrimSpace(s.Loc); s != "" {
+			sitemaps = append(sitemaps, resolveSitemapURL(loc, s))
+		}
+	}
+	for _, u := range v.URLs {
+		l := strings.TrimSpace(u.Loc)
+		if l == "" {
+			continue
+		}
+		urls = append(urls, SitemapURL{
+			Loc:        resolveSitemapURL(loc, l),
+			LastMod:    parseLastMod(strings.TrimSpace(u.LastMod)),
+			ChangeFreq: strings.TrimSpace(u.ChangeFreq),
+			Priority:   u.Priority,
+		})
+	}
+	return urls, sitemaps, nil
+}
+
+// resolveSitemapURL resolves the url listed by the sitemap against the url of the sitemap.
+func resolveSitemapURL(base, ref string) string {
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return b.ResolveReference(r).String()
+}
+
+// parseLastMod parses the W3C datetime of a lastmod, zero if invalid.
+func parseLastMod(s string) time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02", "2006-01", "2006"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
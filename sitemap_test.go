@@ -0,0 +1,116 @@
+package puppet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testSitemap = `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+	<url>
+		<loc> /a </loc>
+		<lastmod>2024-01-02</lastmod>
+		<changefreq>daily</changefreq>
+		<priority>0.8</priority>
+	</url>
+	<url>
+		<loc>https://other.example.com/b</loc>
+	</url>
+</urlset>`
+
+func TestReadSitemap(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(testSitemap))
+	w.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(testSitemap))
+	})
+	mux.HandleFunc("/sitemap.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(gz.Bytes())
+	})
+	mux.HandleFunc("/index.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<sitemapindex><sitemap><loc>/sitemap.xml</loc></sitemap><sitemap><loc></loc></sitemap></sitemapindex>`))
+	})
+	mux.HandleFunc("/sitemap.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("https://example.com/a\n\n  /b  \n"))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	urls := []SitemapURL{
+		{Loc: srv.URL + "/a", LastMod: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), ChangeFreq: "daily", Priority: 0.8},
+		{Loc: "https://other.example.com/b"},
+	}
+	tests := []struct {
+		path     string
+		urls     []SitemapURL
+		sitemaps []string
+		err      bool
+	}{
+		{path: "/sitemap.xml", urls: urls},
+		{path: "/sitemap.xml.gz", urls: urls},
+		{path: "/index.xml", sitemaps: []string{srv.URL + "/sitemap.xml"}},
+		{path: "/sitemap.txt", urls: []SitemapURL{{Loc: "https://example.com/a"}, {Loc: srv.URL + "/b"}}},
+		{path: "/missing.xml", err: true},
+	}
+	for _, tt := range tests {
+		urls, sitemaps, err := readSitemap(context.Background(), srv.Client(), srv.URL+tt.path)
+		if (err != nil) != tt.err {
+			t.Errorf("readSitemap(%q) error = %v, want error %v", tt.path, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(urls, tt.urls) {
+			t.Errorf("readSitemap(%q) urls = %+v, want %+v", tt.path, urls, tt.urls)
+		}
+		if !reflect.DeepEqual(sitemaps, tt.sitemaps) {
+			t.Errorf("readSitemap(%q) sitemaps = %v, want %v", tt.path, sitemaps, tt.sitemaps)
+		}
+	}
+}
+
+func TestParseLastMod(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"2024-01-02T03:04:05Z", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{"2024-01-02T03:04:05+02:00", time.Date(2024, 1, 2, 1, 4, 5, 0, time.UTC)},
+		{"2024-01-02T03:04+02:00", time.Date(2024, 1, 2, 1, 4, 0, 0, time.UTC)},
+		{"2024-01-02", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"2024-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"yesterday", time.Time{}},
+		{"", time.Time{}},
+	}
+	for _, tt := range tests {
+		if got := parseLastMod(tt.s); !got.Equal(tt.want) {
+			t.Errorf("parseLastMod(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestResolveSitemapURL(t *testing.T) {
+	tests := []struct {
+		base, ref string
+		want      string
+	}{
+		{"https://example.com/sitemap.xml", "https://other.com/a", "https://other.com/a"},
+		{"https://example.com/sitemap.xml", "/a", "https://example.com/a"},
+		{"https://example.com/sitemaps/index.xml", "page.xml", "https://example.com/sitemaps/page.xml"},
+		{"https://example.com/sitemap.xml", "%zz", "%zz"},
+	}
+	for _, tt := range tests {
+		if got := resolveSitemapURL(tt.base, tt.ref); got != tt.want {
+			t.Errorf("resolveSitemapURL(%q, %q) = %q, want %q", tt.base, tt.ref, got, tt.want)
+		}
+	}
+}
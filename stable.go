@@ -0,0 +1,71 @@
+package puppet
+
+import (
+	"context"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+)
+
+// WaitStable waits until the bounding box of the first node matching the selector stays unchanged for the window,
+// e.g. until an animation has finished.
+func (c *Puppet) WaitStable(sel string, window time.Duration) (err error) {
+	sel = c.selector(sel)
+	return c.run(c.ctx,
+		waitStable(sel, window))
+}
+
+// SetClickStability makes Click and DoubleClick wait until the box of the node stays unchanged
+// for the window before clicking, so animations and layout shifts don't misdirect the click.
+// A zero window disables the wait.
+func (c *Puppet) SetClickStability(window time.Duration) {
+	c.mu.Lock()
+	c.clickStability = window
+	c.mu.Unlock()
+}
+
+// waitStable is an action that waits until the box of the first node matching the selector
+// stays unchanged for the window.
+func waitStable(sel string, window time.Duration) chromedp.Action {
+	interval := window / 4
+	if interval < 16*time.Millisecond {
+		interval = 16 * time.Millisecond
+	}
+	return chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		var last dom.Quad
+		since := time.Now()
+		for {
+			var box *dom.BoxModel
+			err := chromedp.Dimensions(sel, &box, chromedp.NodeVisible).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			if !quadEqual(box.Border, last) {
+				last = box.Border
+				since = time.Now()
+			} else if time.Since(since) >= window {
+				return nil
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+	})
+}
+
+func quadEqual(a, b dom.Quad) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// SetStandby keeps n pre-warmed page targets on about:blank with the init scripts applied,
+// so AcquireTab can hand one out without waiting for a target to start.
+// A zero n stops keeping targets, the ones already warmed stay available.
+func (c *Puppet) SetStandby(n int, scripts ...string) (err error) {
+	c.mu.Lock()
+	c.standbySize = n
+	c.standbyScripts = scripts
+	c.mu.Unlock()
+	return c.fillStandby()
+}
+
+// AcquireTab returns a Puppet bound to a pre-warmed target, or to a new target if none is warm.
+// The target is replaced in the background, closing the returned Puppet closes the target.
+func (c *Puppet) AcquireTab() (tab *Puppet, err error) {
+	c.mu.Lock()
+	var id string
+	if len(c.standby) != 0 {
+		id = c.standby[0]
+		c.standby = c.standby[1:]
+	}
+	c.mu.Unlock()
+
+	if id == "" {
+		id, err = c.warmTarget()
+		if err != nil {
+			return nil, err
+		}
+	}
+	go func() {
+		err := c.fillStandby()
+		if err != nil && c.logger != nil {
+			c.logger.Log(LogError, "standby", "error", err)
+		}
+	}()
+	return c.Tab(id), nil
+}
+
+// fillStandby warms targets until the standby is full.
+func (c *Puppet) fillStandby() (err error) {
+	for {
+		c.mu.Lock()
+		need := c.standbySize - len(c.standby) - c.standbyWarming
+		if need > 0 {
+			c.standbyWarming++
+		}
+		c.mu.Unlock()
+		if need <= 0 {
+			return nil
+		}
+
+		id, err := c.warmTarget()
+		c.mu.Lock()
+		c.standbyWarming--
+		if err == nil {
+			c.standby = append(c.standby, id)
+		}
+		c.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// warmTarget creates a new target on about:blank with the init scripts applied, the target is closed if they fail.
+func (c *Puppet) warmTarget() (id string, err error) {
+	id, err = c.NewTarget("about:blank")
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	scripts := c.standbyScripts
+	c.mu.Unlock()
+
+	actions := []chromedp.Action{}
+	for _, script := range scripts {
+		script := script
+		actions = append(actions, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			_, err := page.AddScriptToEvaluateOnNewDocument(script).
+				Do(ctx, h)
+			return err
+		}))
+	}
+	err = c.runOn(id, actions...)
+	if err != nil {
+		c.CloseTarget(id)
+		return "", err
+	}
+	return id, nil
+}
@@ -0,0 +1,133 @@
+package puppet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// StorageState is a snapshot of the cookies and web storage of a browser.
+type StorageState struct {
+	Cookies []*StateCookie `json:"cookies"`
+	Origins []OriginState  `json:"origins"`
+}
+
+// StateCookie is a cookie of a storage state.
+type StateCookie struct {
+	http.Cookie
+	// Session reports whether the cookie is a session cookie, which has no expiry.
+	Session bool `json:"Session,omitempty"`
+}
+
+// OriginState is a snapshot of the web storage of an origin.
+type OriginState struct {
+	Origin         string            `json:"origin"`
+	LocalStorage   map[string]string `json:"localStorage,omitempty"`
+	SessionStorage map[string]string `json:"sessionStorage,omitempty"`
+}
+
+// StorageState captures all browser cookies and the web storage of the origins of the frames of the current target.
+func (c *Puppet) StorageState() (state *StorageState, err error) {
+	state = &StorageState{}
+	cookies, err := c.Cookies()
+	if err != nil {
+		return nil, err
+	}
+	for _, cookie := range cookies {
+		state.Cookies = append(state.Cookies, &StateCookie{
+			Cookie:  *cookie,
+			Session: cookie.Expires.IsZero(),
+		})
+	}
+
+	frames, err := c.Frames()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, frame := range frames {
+		u, err := url.Parse(frame.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		origin := u.Scheme + "://" + u.Host
+		if seen[origin] {
+			continue
+		}
+		seen[origin] = true
+
+		o := OriginState{
+			Origin: origin,
+		}
+		o.LocalStorage, err = c.LocalStorage(origin)
+		if err != nil {
+			return nil, err
+		}
+		o.SessionStorage, err = c.SessionStorage(origin)
+		if err != nil {
+			return nil, err
+		}
+		state.Origins = append(state.Origins, o)
+	}
+	return state, nil
+}
+
+// SetStorageState restores the cookies and web storage captured by StorageState.
+func (c *Puppet) SetStorageState(state *StorageState) (err error) {
+	cookies := make([]*http.Cookie, 0, len(state.Cookies))
+	for _, sc := range state.Cookies {
+		cookie := sc.Cookie
+		if sc.Session {
+			cookie.Expires = time.Time{}
+		}
+		cookies = append(cookies, &cookie)
+	}
+	err = c.SetCookies(cookies)
+	if err != nil {
+		return err
+	}
+	for _, o := range state.Origins {
+		for k, v := range o.LocalStorage {
+			err = c.SetLocalStorage(o.Origin, k, v)
+			if err != nil {
+				return err
+			}
+		}
+		for k, v := range o.SessionStorage {
+			err = c.SetSessionStorage(o.Origin, k, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SaveState saves the storage state to the file at path as JSON.
+func (c *Puppet) SaveState(path string) (err error) {
+	state, err := c.StorageState()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0600)
+}
+
+// LoadState restores the storage state from the file at path saved by SaveState.
+func (c *Puppet) LoadState(path string) (err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	state := &StorageState{}
+	err = json.Unmarshal(data, state)
+	if err != nil {
+		return err
+	}
+	return c.SetStorageState(state)
+}
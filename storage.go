@@ -0,0 +1,81 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/chromedp"
+)
+
+// LocalStorage retrieves all localStorage items of the origin.
+func (c *Puppet) LocalStorage(origin string) (items map[string]string, err error) {
+	return c.storageItems(origin, true)
+}
+
+// SetLocalStorage sets the localStorage item of the origin.
+func (c *Puppet) SetLocalStorage(origin string, key, value string) (err error) {
+	return c.setStorageItem(origin, true, key, value)
+}
+
+// ClearLocalStorage clears the localStorage of the origin.
+func (c *Puppet) ClearLocalStorage(origin string) (err error) {
+	return c.clearStorage(origin, true)
+}
+
+// SessionStorage retrieves all sessionStorage items of the origin.
+func (c *Puppet) SessionStorage(origin string) (items map[string]string, err error) {
+	return c.storageItems(origin, false)
+}
+
+// SetSessionStorage sets the sessionStorage item of the origin.
+func (c *Puppet) SetSessionStorage(origin string, key, value string) (err error) {
+	return c.setStorageItem(origin, false, key, value)
+}
+
+// ClearSessionStorage clears the sessionStorage of the origin.
+func (c *Puppet) ClearSessionStorage(origin string) (err error) {
+	return c.clearStorage(origin, false)
+}
+
+func (c *Puppet) storageItems(origin string, local bool) (items map[string]string, err error) {
+	err = c.run(c.ctx, chromedp.Tasks{
+		domstorage.Enable(),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			list, err := domstorage.GetDOMStorageItems(storageID(origin, local)).
+				Do(ctx, h)
+			if err != nil {
+				return err
+			}
+			items = make(map[string]string, len(list))
+			for _, item := range list {
+				if len(item) == 2 {
+					items[item[0]] = item[1]
+				}
+			}
+			return nil
+		}),
+	})
+	return items, err
+}
+
+func (c *Puppet) setStorageItem(origin string, local bool, key, value string) (err error) {
+	return c.run(c.ctx, chromedp.Tasks{
+		domstorage.Enable(),
+		domstorage.SetDOMStorageItem(storageID(origin, local), key, value),
+	})
+}
+
+func (c *Puppet) clearStorage(origin string, local bool) (err error) {
+	return c.run(c.ctx, chromedp.Tasks{
+		domstorage.Enable(),
+		domstorage.Clear(storageID(origin, local)),
+	})
+}
+
+func storageID(origin string, local bool) *domstorage.StorageID {
+	return &domstorage.StorageID{
+		SecurityOrigin: origin,
+		IsLocalStorage: local,
+	}
+}
@@ -0,0 +1,194 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Kinds of storage written by a page.
+const (
+	StorageCookie         = "cookie"
+	StorageLocalStorage   = "localStorage"
+	StorageSessionStorage = "sessionStorage"
+)
+
+// StorageWrite is a cookie or web storage item written during a page load.
+type StorageWrite struct {
+	// Origin is the origin that wrote the item.
+	Origin string
+	// Kind is the kind of storage, one of StorageCookie, StorageLocalStorage or StorageSessionStorage.
+	Kind string
+	// Name is the name of the cookie or the key of the web storage item.
+	Name string
+	// URL is the url of the response that set the cookie, empty for the cookies written by scripts and for web storage.
+	URL string
+}
+
+const cookieWriteBinding = "__puppetCookieWrite"
+
+// cookieWriteHook reports the cookies written by the scripts through document.cookie.
+const cookieWriteHook = `(function () {
+	var desc = Object.getOwnPropertyDescriptor(Document.prototype, "cookie");
+	if (!desc || !desc.set) {
+		return;
+	}
+	Object.defineProperty(Document.prototype, "cookie", {
+		configurable: true,
+		enumerable: desc.enumerable,
+		get: desc.get,
+		set: function (v) {
+			desc.set.call(this, v);
+			try {
+				var pair = String(v).split(";")[0];
+				window.` + cookieWriteBinding + `(JSON.stringify({
+					origin: location.origin,
+					name: pair.indexOf("=") < 0 ? "" : pair.slice(0, pair.indexOf("=")).trim()
+				}));
+			} catch (e) {}
+		}
+	});
+})();`
+
+// eventNetworkResponseReceivedExtraInfo is fired with the raw headers of a response,
+// including the Set-Cookie headers, it is unknown to cdproto.
+const eventNetworkResponseReceivedExtraInfo cdproto.MethodType = "Network.responseReceivedExtraInfo"
+
+// responseReceivedExtraInfo is the event of eventNetworkResponseReceivedExtraInfo.
+type responseReceivedExtraInfo struct {
+	RequestID network.RequestID      `json:"requestId"`
+	Headers   map[string]interface{} `json:"headers"`
+}
+
+// CollectStorageWrites starts collecting the cookies set by responses and by scripts, and the web storage items
+// written by the current target, the writes collected are reset on every navigation of the main frame.
+// The cookies written by scripts are collected from the pages loaded afterwards.
+func (c *Puppet) CollectStorageWrites() (err error) {
+	// the binding calls are reported to the session which added the binding
+	err = c.runSession(runtime.AddBinding(cookieWriteBinding))
+	if err != nil {
+		return err
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(cookieWriteHook).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return err
+	}
+
+	requests := map[network.RequestID]string{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				c.mu.Lock()
+				c.storageWrites = nil
+				c.mu.Unlock()
+			}
+		case *runtime.EventBindingCalled:
+			if ev.Name != cookieWriteBinding {
+				return
+			}
+			var v struct {
+				Origin string `json:"origin"`
+				Name   string `json:"name"`
+			}
+			if json.Unmarshal([]byte(ev.Payload), &v) != nil {
+				return
+			}
+			c.mu.Lock()
+			c.storageWrites = append(c.storageWrites, StorageWrite{
+				Origin: v.Origin,
+				Kind:   StorageCookie,
+				Name:   v.Name,
+			})
+			c.mu.Unlock()
+		case *requestWillBeSent:
+			requests[ev.RequestID] = ev.Request.URL
+		case *network.EventLoadingFinished:
+			delete(requests, ev.RequestID)
+		case *network.EventLoadingFailed:
+			delete(requests, ev.RequestID)
+		case *responseReceivedExtraInfo:
+			rawurl, ok := requests[ev.RequestID]
+			if !ok {
+				return
+			}
+			delete(requests, ev.RequestID)
+			header := http.Header{}
+			for k, v := range ev.Headers {
+				s, ok := v.(string)
+				if !ok || !strings.EqualFold(k, "Set-Cookie") {
+					continue
+				}
+				for _, line := range strings.Split(s, "\n") {
+					header.Add("Set-Cookie", line)
+				}
+			}
+			cookies := (&http.Response{Header: header}).Cookies()
+			if len(cookies) == 0 {
+				return
+			}
+			origin := rawurl
+			if u, err := url.Parse(rawurl); err == nil {
+				origin = u.Scheme + "://" + u.Host
+			}
+			c.mu.Lock()
+			for _, cookie := range cookies {
+				c.storageWrites = append(c.storageWrites, StorageWrite{
+					Origin: origin,
+					Kind:   StorageCookie,
+					Name:   cookie.Name,
+					URL:    rawurl,
+				})
+			}
+			c.mu.Unlock()
+		case *domstorage.EventDomStorageItemAdded:
+			c.addStorageWrite(ev.StorageID, ev.Key)
+		case *domstorage.EventDomStorageItemUpdated:
+			c.addStorageWrite(ev.StorageID, ev.Key)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventRuntimeBindingCalled,
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkLoadingFailed,
+		eventNetworkResponseReceivedExtraInfo,
+		cdproto.EventDOMStorageDomStorageItemAdded,
+		cdproto.EventDOMStorageDomStorageItemUpdated,
+	)
+}
+
+// StorageWrites returns the cookies and web storage items written since the last navigation.
+func (c *Puppet) StorageWrites() []StorageWrite {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]StorageWrite(nil), c.storageWrites...)
+}
+
+func (c *Puppet) addStorageWrite(id *domstorage.StorageID, key string) {
+	kind := StorageSessionStorage
+	if id.IsLocalStorage {
+		kind = StorageLocalStorage
+	}
+	c.mu.Lock()
+	c.storageWrites = append(c.storageWrites, StorageWrite{
+		Origin: id.SecurityOrigin,
+		Kind:   kind,
+		Name:   key,
+	})
+	c.mu.Unlock()
+}
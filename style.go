@@ -0,0 +1,52 @@
+package puppet
+
+import (
+	"math"
+
+	"github.com/chromedp/cdproto/css"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/chromedp"
+)
+
+// ComputedStyle retrieves the computed style of the first node matching the selector.
+func (c *Puppet) ComputedStyle(sel string) (style map[string]string, err error) {
+	sel = c.selector(sel)
+	var props []*css.ComputedProperty
+	err = c.run(c.ctx,
+		chromedp.ComputedStyle(sel, &props))
+	if err != nil {
+		return nil, err
+	}
+	style = make(map[string]string, len(props))
+	for _, prop := range props {
+		style[prop.Name] = prop.Value
+	}
+	return style, nil
+}
+
+// BoundingBox retrieves the bounding box of the border box of the first node matching the selector,
+// in CSS pixels relative to the viewport.
+func (c *Puppet) BoundingBox(sel string) (x, y, width, height float64, err error) {
+	sel = c.selector(sel)
+	var box *dom.BoxModel
+	err = c.run(c.ctx,
+		chromedp.Dimensions(sel, &box, chromedp.NodeVisible))
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	x, y, width, height = quadBounds(box.Border)
+	return x, y, width, height, nil
+}
+
+// quadBounds returns the bounding box of the quad.
+func quadBounds(q dom.Quad) (x, y, width, height float64) {
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for i := 0; i+1 < len(q); i += 2 {
+		minX = math.Min(minX, q[i])
+		maxX = math.Max(maxX, q[i])
+		minY = math.Min(minY, q[i+1])
+		maxY = math.Max(maxY, q[i+1])
+	}
+	return minX, minY, maxX - minX, maxY - minY
+}
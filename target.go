@@ -0,0 +1,252 @@
+package puppet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+)
+
+// TargetInfo is the information of a target.
+type TargetInfo struct {
+	ID string
+	// Type is the type of the target, e.g. "page", "iframe", "worker" or "service_worker".
+	Type      string
+	Title     string
+	URL       string
+	Attached  bool
+	OpenerID  string
+	ContextID string
+}
+
+func newTargetInfo(info *target.Info) TargetInfo {
+	return TargetInfo{
+		ID:        string(info.TargetID),
+		Type:      info.Type,
+		Title:     info.Title,
+		URL:       info.URL,
+		Attached:  info.Attached,
+		OpenerID:  string(info.OpenerID),
+		ContextID: string(info.BrowserContextID),
+	}
+}
+
+// FindTarget returns the first target of type page that matches the predicate.
+func (c *Puppet) FindTarget(match func(TargetInfo) bool) (info TargetInfo, ok bool, err error) {
+	targets, err := c.Targets()
+	if err != nil {
+		return info, false, err
+	}
+	for _, t := range targets {
+		if t.Type == "page" && match(t) {
+			return t, true, nil
+		}
+	}
+	return info, false, nil
+}
+
+// OnTargetCreated calls fn with every page target created after the call,
+// e.g. by window.open or target=_blank links.
+func (c *Puppet) OnTargetCreated(fn func(TargetInfo)) (err error) {
+	c.mu.Lock()
+	c.targetCreated = append(c.targetCreated, fn)
+	c.mu.Unlock()
+	return c.discoverTargets()
+}
+
+// WaitForNewTarget waits until a new page target is created and managed,
+// so it can be activated with SetTarget and driven.
+func (c *Puppet) WaitForNewTarget(ctx context.Context) (info TargetInfo, err error) {
+	ch := make(chan TargetInfo, 1)
+	c.mu.Lock()
+	if c.targetWaiters == nil {
+		c.targetWaiters = map[chan TargetInfo]struct{}{}
+	}
+	c.targetWaiters[ch] = struct{}{}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.targetWaiters, ch)
+		c.mu.Unlock()
+	}()
+
+	err = c.discoverTargets()
+	if err != nil {
+		return info, err
+	}
+
+	select {
+	case <-ctx.Done():
+		return info, ctx.Err()
+	case info = <-ch:
+	}
+
+	_, err = c.handler(info.ID)
+	if err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+// discoverTargets starts the discovery of the targets once.
+func (c *Puppet) discoverTargets() (err error) {
+	c.mu.Lock()
+	discovering := c.discovering
+	c.discovering = true
+	c.mu.Unlock()
+	if discovering {
+		return nil
+	}
+
+	// the discovery reports the existing targets as created first
+	existing := map[string]bool{}
+	targets, err := c.Targets()
+	if err == nil {
+		for _, t := range targets {
+			existing[t.ID] = true
+		}
+		err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+			created, ok := ev.(*target.EventTargetCreated)
+			if !ok || created.TargetInfo.Type != "page" {
+				return
+			}
+			if existing[string(created.TargetInfo.TargetID)] {
+				delete(existing, string(created.TargetInfo.TargetID))
+				return
+			}
+			c.targetCreatedEvent(newTargetInfo(created.TargetInfo))
+		}, cdproto.EventTargetTargetCreated)
+	}
+	if err == nil {
+		// the target events are reported to the session which discovers the targets
+		err = c.runSession(target.SetDiscoverTargets(true))
+	}
+	if err != nil {
+		c.mu.Lock()
+		c.discovering = false
+		c.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// targetCreatedEvent notifies the callbacks and the waiters of the new target.
+func (c *Puppet) targetCreatedEvent(info TargetInfo) {
+	c.mu.Lock()
+	fns := append(([]func(TargetInfo))(nil), c.targetCreated...)
+	for ch := range c.targetWaiters {
+		select {
+		case ch <- info:
+		default:
+		}
+	}
+	c.mu.Unlock()
+	for _, fn := range fns {
+		fn(info)
+	}
+}
+
+// ErrTooManyTargets is returned when creating a target would exceed the maximum number of open targets.
+var ErrTooManyTargets = errors.New("too many open targets")
+
+// CloseOtherTargets closes all page targets except the one with the specified id.
+func (c *Puppet) CloseOtherTargets(keepID string) (err error) {
+	return c.closeTargets(func(t TargetInfo) bool {
+		return t.ID != keepID
+	})
+}
+
+// CloseAllTargets closes all page targets.
+func (c *Puppet) CloseAllTargets() (err error) {
+	return c.closeTargets(func(t TargetInfo) bool {
+		return true
+	})
+}
+
+// SetMaxTargets caps the number of open page targets, creating a target beyond the cap fails with ErrTooManyTargets
+// and windows opened by pages beyond the cap are closed. The targets already open are kept. A zero max removes the cap.
+func (c *Puppet) SetMaxTargets(max int) (err error) {
+	c.mu.Lock()
+	c.maxTargets = max
+	watching := c.watchingMax
+	if max > 0 {
+		c.watchingMax = true
+	}
+	c.mu.Unlock()
+	if max <= 0 || watching {
+		return nil
+	}
+	// the callback stays registered even if the discovery fails, so it is registered once
+	return c.OnTargetCreated(func(info TargetInfo) {
+		c.mu.Lock()
+		max := c.maxTargets
+		c.mu.Unlock()
+		if max <= 0 {
+			return
+		}
+		// the new target is already counted
+		n, err := c.countPageTargets()
+		if err == nil && n > max {
+			c.run(c.ctx,
+				closeTarget(info.ID))
+		}
+	})
+}
+
+// checkMaxTargets returns ErrTooManyTargets if the number of open page targets reaches the cap.
+func (c *Puppet) checkMaxTargets() (err error) {
+	c.mu.Lock()
+	max := c.maxTargets
+	c.mu.Unlock()
+	if max <= 0 {
+		return nil
+	}
+	n, err := c.countPageTargets()
+	if err != nil {
+		return err
+	}
+	if n >= max {
+		return ErrTooManyTargets
+	}
+	return nil
+}
+
+func (c *Puppet) countPageTargets() (n int, err error) {
+	targets, err := c.Targets()
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range targets {
+		if t.Type == "page" {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (c *Puppet) closeTargets(match func(TargetInfo) bool) (err error) {
+	targets, err := c.Targets()
+	if err != nil {
+		return err
+	}
+	for _, t := range targets {
+		if t.Type != "page" || !match(t) {
+			continue
+		}
+		err = c.run(c.ctx,
+			closeTarget(t.ID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ActivateTarget brings the target with the specified id to the front,
+// so the target being automated is visible in headful mode.
+func (c *Puppet) ActivateTarget(id string) (err error) {
+	return c.run(c.ctx,
+		target.ActivateTarget(target.ID(id)))
+}
@@ -0,0 +1,80 @@
+package puppet
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// TextOptions configures the normalization of texts before comparing them.
+type TextOptions struct {
+	// StripDiacritics removes the diacritics, e.g. "café" becomes "cafe".
+	StripDiacritics bool
+	// IgnoreCase folds the case.
+	IgnoreCase bool
+	// Compatibility normalizes to NFKC instead of NFC, e.g. "ﬁ" becomes "fi" and full-width letters become ASCII.
+	Compatibility bool
+}
+
+// NormalizeText normalizes the Unicode form of the text and collapses the runs of whitespace,
+// including non-breaking and zero-width spaces, to single spaces, and trims it.
+func NormalizeText(s string, opts TextOptions) string {
+	if opts.StripDiacritics {
+		t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if r, _, err := transform.String(t, s); err == nil {
+			s = r
+		}
+	}
+	if opts.Compatibility {
+		s = norm.NFKC.String(s)
+	} else {
+		s = norm.NFC.String(s)
+	}
+	if opts.IgnoreCase {
+		s = cases.Fold().String(s)
+	}
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff':
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.FieldsFunc(s, unicode.IsSpace), " ")
+}
+
+// TextEqual reports whether the texts are equal once normalized.
+func TextEqual(a, b string, opts TextOptions) bool {
+	return NormalizeText(a, opts) == NormalizeText(b, opts)
+}
+
+// TextContains reports whether the text contains the substring once both are normalized.
+func TextContains(s, substr string, opts TextOptions) bool {
+	return strings.Contains(NormalizeText(s, opts), NormalizeText(substr, opts))
+}
+
+// TextEqual reports whether the text of the first node matching the selector equals the text once both are normalized,
+// and returns the normalized text of the node.
+func (c *Puppet) TextEqual(sel string, want string, opts TextOptions) (ok bool, got string, err error) {
+	text, err := c.Text(sel)
+	if err != nil {
+		return false, "", err
+	}
+	got = NormalizeText(text, opts)
+	return got == NormalizeText(want, opts), got, nil
+}
+
+// TextContains reports whether the text of the first node matching the selector contains the substring
+// once both are normalized, and returns the normalized text of the node.
+func (c *Puppet) TextContains(sel string, substr string, opts TextOptions) (ok bool, got string, err error) {
+	text, err := c.Text(sel)
+	if err != nil {
+		return false, "", err
+	}
+	got = NormalizeText(text, opts)
+	return strings.Contains(got, NormalizeText(substr, opts)), got, nil
+}
@@ -0,0 +1,205 @@
+package puppet
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"golang.org/x/net/publicsuffix"
+)
+
+// ThirdParty is the summary of the requests to an external origin.
+type ThirdParty struct {
+	Origin string
+	// Category is the category of the origin, e.g. "analytics", "ads", "cdn", or empty if unknown.
+	Category string
+	Requests int
+	Bytes    int64
+}
+
+// ThirdPartyCategories maps the known domains to their categories,
+// subdomains of a domain belong to the same category.
+var ThirdPartyCategories = map[string]string{
+	"google-analytics.com":          "analytics",
+	"googletagmanager.com":          "analytics",
+	"analytics.google.com":          "analytics",
+	"hotjar.com":                    "analytics",
+	"segment.com":                   "analytics",
+	"segment.io":                    "analytics",
+	"mixpanel.com":                  "analytics",
+	"amplitude.com":                 "analytics",
+	"newrelic.com":                  "analytics",
+	"nr-data.net":                   "analytics",
+	"doubleclick.net":               "ads",
+	"googlesyndication.com":         "ads",
+	"googleadservices.com":          "ads",
+	"adnxs.com":                     "ads",
+	"criteo.com":                    "ads",
+	"taboola.com":                   "ads",
+	"outbrain.com":                  "ads",
+	"amazon-adsystem.com":           "ads",
+	"facebook.net":                  "social",
+	"facebook.com":                  "social",
+	"twitter.com":                   "social",
+	"linkedin.com":                  "social",
+	"cloudflare.com":                "cdn",
+	"cdnjs.cloudflare.com":          "cdn",
+	"jsdelivr.net":                  "cdn",
+	"unpkg.com":                     "cdn",
+	"cloudfront.net":                "cdn",
+	"akamaihd.net":                  "cdn",
+	"fastly.net":                    "cdn",
+	"googleapis.com":                "cdn",
+	"gstatic.com":                   "cdn",
+	"fonts.googleapis.com":          "fonts",
+	"fonts.gstatic.com":             "fonts",
+	"use.typekit.net":               "fonts",
+	"stripe.com":                    "payment",
+	"paypal.com":                    "payment",
+	"intercom.io":                   "support",
+	"zendesk.com":                   "support",
+	"sentry.io":                     "monitoring",
+	"browser.sentry-cdn.com":        "monitoring",
+	"youtube.com":                   "media",
+	"ytimg.com":                     "media",
+	"vimeo.com":                     "media",
+	"recaptcha.net":                 "security",
+	"hcaptcha.com":                  "security",
+	"cookielaw.org":                 "consent",
+	"onetrust.com":                  "consent",
+	"cookiebot.com":                 "consent",
+	"consensu.org":                  "consent",
+	"quantserve.com":                "ads",
+	"scorecardresearch.com":         "analytics",
+	"bing.com":                      "ads",
+	"clarity.ms":                    "analytics",
+	"tiktok.com":                    "social",
+	"snapchat.com":                  "social",
+	"pinterest.com":                 "social",
+	"cdn.shopify.com":               "cdn",
+	"ajax.googleapis.com":           "cdn",
+	"maps.googleapis.com":           "maps",
+	"connect.facebook.net":          "social",
+	"static.cloudflareinsights.com": "analytics",
+}
+
+// CollectThirdParties starts collecting the requests of the current target to external origins,
+// the requests collected are reset on every navigation of the main frame.
+func (c *Puppet) CollectThirdParties() (err error) {
+	site := ""
+	requests := map[network.RequestID]string{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				site = siteOf(ev.Frame.URL)
+				c.mu.Lock()
+				c.thirdParties = nil
+				c.mu.Unlock()
+			}
+		case *requestWillBeSent:
+			u, err := url.Parse(ev.Request.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss") {
+				return
+			}
+			if site == "" || siteOf(ev.Request.URL) == site {
+				return
+			}
+			origin := u.Scheme + "://" + u.Host
+			requests[ev.RequestID] = origin
+			c.mu.Lock()
+			if c.thirdParties == nil {
+				c.thirdParties = map[string]*ThirdParty{}
+			}
+			tp, ok := c.thirdParties[origin]
+			if !ok {
+				tp = &ThirdParty{
+					Origin:   origin,
+					Category: thirdPartyCategory(u.Hostname()),
+				}
+				c.thirdParties[origin] = tp
+			}
+			tp.Requests++
+			c.mu.Unlock()
+		case *network.EventLoadingFinished:
+			origin, ok := requests[ev.RequestID]
+			if !ok {
+				return
+			}
+			delete(requests, ev.RequestID)
+			c.mu.Lock()
+			if tp, ok := c.thirdParties[origin]; ok {
+				tp.Bytes += int64(ev.EncodedDataLength)
+			}
+			c.mu.Unlock()
+		case *network.EventLoadingFailed:
+			delete(requests, ev.RequestID)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventNetworkRequestWillBeSent,
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkLoadingFailed,
+	)
+}
+
+// ThirdParties returns the summary of the external origins contacted since the last navigation,
+// ordered by the number of requests.
+func (c *Puppet) ThirdParties() []ThirdParty {
+	c.mu.Lock()
+	res := make([]ThirdParty, 0, len(c.thirdParties))
+	for _, tp := range c.thirdParties {
+		res = append(res, *tp)
+	}
+	c.mu.Unlock()
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Requests != res[j].Requests {
+			return res[i].Requests > res[j].Requests
+		}
+		return res[i].Origin < res[j].Origin
+	})
+	return res
+}
+
+// siteOf returns the registrable domain of the url.
+func siteOf(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	host := u.Hostname()
+	site, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return site
+}
+
+// hostOf returns the lower case host name of the url.
+func hostOf(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// thirdPartyCategory returns the category of the most specific known domain the host belongs to.
+func thirdPartyCategory(host string) string {
+	for host != "" {
+		if category, ok := ThirdPartyCategories[host]; ok {
+			return category
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package puppet
+
+import (
+	"testing"
+)
+
+func TestCollectThirdParties(t *testing.T) {
+	p, b := newFakePuppet(t)
+	err := p.CollectThirdParties()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Page.frameNavigated", `{"frame":{"id":"F","loaderId":"L","url":"https://example.com/","securityOrigin":"https://example.com","mimeType":"text/html"}}`)
+	b.emit("Network.requestWillBeSent", `{"requestId":"1","loaderId":"L","documentURL":"https://example.com/","request":{"url":"https://www.google-analytics.com/analytics.js","method":"GET","headers":{}}}`)
+	b.emit("Network.loadingFinished", `{"requestId":"1","encodedDataLength":100}`)
+	eventually(t, func() bool {
+		tps := p.ThirdParties()
+		return len(tps) == 1 && tps[0].Bytes == 100
+	})
+	tp := p.ThirdParties()[0]
+	if tp.Origin != "https://www.google-analytics.com" || tp.Category != "analytics" || tp.Requests != 1 {
+		t.Errorf("got third party %+v", tp)
+	}
+}
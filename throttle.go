@@ -0,0 +1,132 @@
+package puppet
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+)
+
+// LimitThirdParty caps the number of concurrent requests of the current target to third-party sites,
+// the requests beyond the cap wait until others finish, so heavy pages load reliably on constrained workers.
+// A cap not positive leaves the requests unlimited.
+func (c *Puppet) LimitThirdParty(max int) (err error) {
+	if max <= 0 {
+		return nil
+	}
+	sem := make(chan struct{}, max)
+	var mu sync.Mutex
+	inflight := map[network.RequestID]bool{}
+	site := ""
+
+	release := func(id network.RequestID) {
+		mu.Lock()
+		ok := inflight[id]
+		delete(inflight, id)
+		mu.Unlock()
+		if ok {
+			<-sem
+		}
+	}
+
+	err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				mu.Lock()
+				site = siteOf(ev.Frame.URL)
+				mu.Unlock()
+			}
+		case *network.EventLoadingFinished:
+			release(ev.RequestID)
+		case *network.EventLoadingFailed:
+			release(ev.RequestID)
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventNetworkLoadingFinished,
+		cdproto.EventNetworkLoadingFailed,
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.intercept(nil, false, func(r *Request) {
+		mu.Lock()
+		first := site == "" || r.IsNavigation || siteOf(r.URL) == site
+		// the redirects of a request keep its slot
+		held := inflight[r.networkID]
+		mu.Unlock()
+		if first || held {
+			return
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-r.ctx.Done():
+			return
+		}
+		mu.Lock()
+		inflight[r.networkID] = true
+		mu.Unlock()
+		// the slot of a request failed or fulfilled by a later interceptor is released even if the browser reports nothing
+		r.onResolved(func() {
+			release(r.networkID)
+		})
+	})
+}
+
+// Deprioritize holds back the requests of the current target to the third parties of the categories,
+// e.g. "analytics" or "ads" of ThirdPartyCategories, until the page has loaded or at most for the delay.
+func (c *Puppet) Deprioritize(delay time.Duration, categories ...string) (err error) {
+	deprioritized := map[string]bool{}
+	for _, category := range categories {
+		deprioritized[category] = true
+	}
+
+	var mu sync.Mutex
+	loaded := make(chan struct{})
+	err = c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch ev := ev.(type) {
+		case *page.EventFrameNavigated:
+			if ev.Frame.ParentID == "" {
+				select {
+				case <-loaded:
+					loaded = make(chan struct{})
+				default:
+				}
+			}
+		case *page.EventLoadEventFired:
+			select {
+			case <-loaded:
+			default:
+				close(loaded)
+			}
+		}
+	},
+		cdproto.EventPageFrameNavigated,
+		cdproto.EventPageLoadEventFired,
+	)
+	if err != nil {
+		return err
+	}
+
+	return c.intercept(nil, false, func(r *Request) {
+		if r.IsNavigation || !deprioritized[thirdPartyCategory(hostOf(r.URL))] {
+			return
+		}
+		mu.Lock()
+		wait := loaded
+		mu.Unlock()
+		select {
+		case <-wait:
+		case <-time.After(delay):
+		case <-r.ctx.Done():
+		}
+	})
+}
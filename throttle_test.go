@@ -0,0 +1,31 @@
+package puppet
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLimitThirdPartyReleasesResolvedRequests(t *testing.T) {
+	p, b := newFakePuppet(t)
+	err := p.LimitThirdParty(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the requests are failed after taking a slot, so the browser reports no loading for them
+	err = p.intercept(nil, false, func(r *Request) {
+		r.Fail()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Page.frameNavigated", `{"frame":{"id":"F","loaderId":"L","url":"https://example.com/","securityOrigin":"https://example.com","mimeType":"text/html"}}`)
+	time.Sleep(time.Second / 10)
+	for i := 0; i != 3; i++ {
+		b.emit("Fetch.requestPaused", fmt.Sprintf(`{"requestId":"r%d","networkId":"n%d","frameId":"F","resourceType":"Script","request":{"url":"https://cdn%d.example.net/x.js","method":"GET","headers":{}}}`, i, i, i))
+	}
+	eventually(t, func() bool {
+		return b.called("Fetch.failRequest") == 3
+	})
+}
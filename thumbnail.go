@@ -0,0 +1,139 @@
+package puppet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// thumbnailWidth and thumbnailHeight are the size of the desktop viewport pages are laid out in for thumbnails.
+const (
+	thumbnailWidth  = 1280
+	thumbnailHeight = 800
+)
+
+// waitRendered waits until the fonts and the images of the page have loaded, at most for 3 seconds.
+const waitRendered = `Promise.race([
+	Promise.all([document.fonts ? document.fonts.ready : null].concat(Array.prototype.filter.call(document.images, function (img) {
+		return !img.complete;
+	}).map(function (img) {
+		return new Promise(function (resolve) {
+			img.addEventListener("load", resolve);
+			img.addEventListener("error", resolve);
+		});
+	}))),
+	new Promise(function (resolve) { setTimeout(resolve, 3000) })
+]).then(function () { return true })`
+
+// Thumbnail navigates to the url and captures the top of the page as a JPEG image of w x h pixels,
+// the page is laid out in a desktop viewport, center-cropped to the aspect ratio and scaled down, e.g. for link previews.
+func (c *Puppet) Thumbnail(url string, w, h int64) (res []byte, err error) {
+	if w <= 0 || h <= 0 {
+		return nil, fmt.Errorf("thumbnail: invalid size %dx%d", w, h)
+	}
+	err = c.run(c.ctx,
+		emulation.SetDeviceMetricsOverride(thumbnailWidth, thumbnailHeight, 1, false))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		v := c.Overrides().Viewport
+		var restore chromedp.Action = emulation.ClearDeviceMetricsOverride()
+		if v != nil {
+			restore = emulation.SetDeviceMetricsOverride(v.Width, v.Height, v.DeviceScaleFactor, v.Mobile)
+		}
+		errRestore := c.run(c.ctx, restore)
+		if err == nil {
+			err = errRestore
+		}
+	}()
+
+	var ok bool
+	var shot []byte
+	err = c.run(c.ctx, chromedp.Tasks{
+		c.navigate(url),
+		waitComplete,
+		chromedp.Evaluate(waitRendered, &ok, awaitPromise),
+		chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+			shot, err = page.CaptureScreenshot().
+				Do(ctx, h)
+			return err
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		return nil, err
+	}
+	thumb := resizeImage(img, thumbnailCrop(img.Bounds(), int(w), int(h)), int(w), int(h))
+	var buf bytes.Buffer
+	err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// thumbnailCrop returns the largest rectangle of the aspect ratio w:h centered in the bounds.
+func thumbnailCrop(bounds image.Rectangle, w, h int) image.Rectangle {
+	bw, bh := bounds.Dx(), bounds.Dy()
+	cw, ch := bw, bw*h/w
+	if ch > bh {
+		cw, ch = bh*w/h, bh
+	}
+	if cw < 1 {
+		cw = 1
+	}
+	if ch < 1 {
+		ch = 1
+	}
+	x := bounds.Min.X + (bw-cw)/2
+	y := bounds.Min.Y + (bh-ch)/2
+	return image.Rect(x, y, x+cw, y+ch)
+}
+
+// resizeImage scales the rectangle of the image to w x h pixels, averaging the source pixels covered by each pixel.
+func resizeImage(src image.Image, r image.Rectangle, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := r.Min.Y + y*r.Dy()/h
+		y1 := r.Min.Y + (y+1)*r.Dy()/h
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < w; x++ {
+			x0 := r.Min.X + x*r.Dx()/w
+			x1 := r.Min.X + (x+1)*r.Dx()/w
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var sr, sg, sb, sa, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					sr, sg, sb, sa = sr+uint64(cr), sg+uint64(cg), sb+uint64(cb), sa+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(sr / n),
+				G: uint16(sg / n),
+				B: uint16(sb / n),
+				A: uint16(sa / n),
+			})
+		}
+	}
+	return dst
+}
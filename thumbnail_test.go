@@ -0,0 +1,25 @@
+package puppet
+
+import (
+	"image"
+	"testing"
+)
+
+func TestThumbnailCrop(t *testing.T) {
+	tests := []struct {
+		bounds image.Rectangle
+		w, h   int
+		want   image.Rectangle
+	}{
+		{image.Rect(0, 0, 1280, 800), 320, 200, image.Rect(0, 0, 1280, 800)},
+		{image.Rect(0, 0, 1280, 1000), 320, 200, image.Rect(0, 100, 1280, 900)},
+		{image.Rect(0, 0, 1000, 800), 200, 200, image.Rect(100, 0, 900, 800)},
+		{image.Rect(10, 10, 110, 60), 1, 1, image.Rect(35, 10, 85, 60)},
+		{image.Rect(0, 0, 1, 1), 1000, 1, image.Rect(0, 0, 1, 1)},
+	}
+	for _, tt := range tests {
+		if got := thumbnailCrop(tt.bounds, tt.w, tt.h); got != tt.want {
+			t.Errorf("thumbnailCrop(%v, %d, %d) = %v, want %v", tt.bounds, tt.w, tt.h, got, tt.want)
+		}
+	}
+}
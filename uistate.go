@@ -0,0 +1,105 @@
+package puppet
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/chromedp"
+)
+
+// UIState is a snapshot of the state of the user interface of a target.
+type UIState struct {
+	URL     string  `json:"url"`
+	ScrollX float64 `json:"scrollX"`
+	ScrollY float64 `json:"scrollY"`
+	// Form are the values of the input, select and textarea nodes by CSS selector,
+	// checkboxes and radio buttons have the value "true" or "false".
+	Form    map[string]string `json:"form"`
+	Storage *StorageState     `json:"storage"`
+}
+
+const captureForm = `(function () {
+	function path(el) {
+		if (el.id) {
+			return "#" + CSS.escape(el.id);
+		}
+		var parts = [];
+		for (; el && el.nodeType === 1 && el !== document.documentElement; el = el.parentElement) {
+			if (el.id) {
+				parts.unshift("#" + CSS.escape(el.id));
+				break;
+			}
+			var i = 1;
+			for (var s = el.previousElementSibling; s; s = s.previousElementSibling) {
+				if (s.nodeName === el.nodeName) {
+					i++;
+				}
+			}
+			parts.unshift(el.nodeName.toLowerCase() + ":nth-of-type(" + i + ")");
+		}
+		return parts.join(" > ");
+	}
+	var form = {};
+	document.querySelectorAll("input, select, textarea").forEach(function (el) {
+		if (el.type === "file" || el.type === "password") {
+			return;
+		}
+		form[path(el)] = (el.type === "checkbox" || el.type === "radio") ? String(el.checked) : el.value;
+	});
+	return { url: location.href, scrollX: window.scrollX, scrollY: window.scrollY, form: form };
+})()`
+
+const restoreForm = `(function (state) {
+	Object.keys(state.form || {}).forEach(function (sel) {
+		var el = document.querySelector(sel);
+		if (!el) {
+			return;
+		}
+		if (el.type === "checkbox" || el.type === "radio") {
+			el.checked = state.form[sel] === "true";
+		} else {
+			el.value = state.form[sel];
+		}
+		el.dispatchEvent(new Event("input", { bubbles: true }));
+		el.dispatchEvent(new Event("change", { bubbles: true }));
+	});
+	window.scrollTo(state.scrollX, state.scrollY);
+	return true;
+})(%s)`
+
+// CaptureState captures the url, scroll position, form values and storage of the current target.
+func (c *Puppet) CaptureState() (state *UIState, err error) {
+	state = &UIState{}
+	err = c.run(c.ctx,
+		chromedp.Evaluate(captureForm, state))
+	if err != nil {
+		return nil, err
+	}
+	state.Storage, err = c.StorageState()
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// RestoreState restores the storage, navigates to the url and restores the form values and scroll position
+// captured by CaptureState.
+func (c *Puppet) RestoreState(state *UIState) (err error) {
+	if state.Storage != nil {
+		err = c.SetStorageState(state.Storage)
+		if err != nil {
+			return err
+		}
+	}
+	err = c.Navigate(state.URL)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	return c.run(c.ctx,
+		chromedp.Evaluate(fmt.Sprintf(restoreForm, data), &ok))
+}
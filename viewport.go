@@ -0,0 +1,44 @@
+package puppet
+
+import (
+	"github.com/chromedp/cdproto/emulation"
+)
+
+// Viewport is the emulated device metrics of a target.
+type Viewport struct {
+	Width             int64
+	Height            int64
+	DeviceScaleFactor float64
+	Mobile            bool
+}
+
+// SetViewport overrides the device metrics of the current target.
+func (c *Puppet) SetViewport(width, height int64, deviceScaleFactor float64, mobile bool) (err error) {
+	err = c.run(c.ctx,
+		emulation.SetDeviceMetricsOverride(width, height, deviceScaleFactor, mobile))
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.Viewport = &Viewport{
+			Width:             width,
+			Height:            height,
+			DeviceScaleFactor: deviceScaleFactor,
+			Mobile:            mobile,
+		}
+	})
+	return nil
+}
+
+// ResetViewport clears the device metrics override of the current target.
+func (c *Puppet) ResetViewport() (err error) {
+	err = c.run(c.ctx,
+		emulation.ClearDeviceMetricsOverride())
+	if err != nil {
+		return err
+	}
+	c.updateOverrides(func(o *Overrides) {
+		o.Viewport = nil
+	})
+	return nil
+}
@@ -0,0 +1,202 @@
+package puppet
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Region is a rectangle of the page in CSS pixels.
+type Region struct {
+	X, Y, Width, Height float64
+}
+
+// ScreenshotOptions configures the comparison of a screenshot with its golden image.
+type ScreenshotOptions struct {
+	// Dir is the directory of the golden images, "testdata/screenshots" if empty.
+	Dir string
+	// Threshold is the perceived color difference, from 0 to 1, above which a pixel differs, 0.1 if zero.
+	Threshold float64
+	// MaxDiffRatio is the ratio of differing pixels tolerated, from 0 to 1.
+	MaxDiffRatio float64
+	// IgnoreSelectors are the selectors of the nodes ignored, e.g. timestamps or ads.
+	IgnoreSelectors []string
+	// IgnoreRegions are the regions of the page ignored.
+	IgnoreRegions []Region
+	// Update overwrites the golden image with the screenshot.
+	Update bool
+}
+
+// ScreenshotDiff is the result of the comparison of a screenshot with its golden image.
+type ScreenshotDiff struct {
+	// Match reports whether the screenshot matches the golden image.
+	Match bool
+	// DiffPixels is the number of differing pixels.
+	DiffPixels int
+	// DiffRatio is the ratio of differing pixels.
+	DiffRatio float64
+	// Golden is the path of the golden image.
+	Golden string
+	// Diff is the path of the diff image written on mismatch, with the differing pixels in red.
+	Diff string
+}
+
+// CompareScreenshot compares a screenshot of the current page with the golden image of the name,
+// the golden image is written if it does not exist yet or opts.Update is set.
+// On mismatch the screenshot and the diff image are written next to the golden image.
+func (c *Puppet) CompareScreenshot(name string, opts ScreenshotOptions) (diff *ScreenshotDiff, err error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = filepath.Join("testdata", "screenshots")
+	}
+	threshold := opts.Threshold
+	if threshold == 0 {
+		threshold = 0.1
+	}
+	diff = &ScreenshotDiff{
+		Golden: filepath.Join(dir, name+".png"),
+	}
+
+	shot, err := c.Screenshot()
+	if err != nil {
+		return nil, err
+	}
+	actual, err := png.Decode(bytes.NewReader(shot))
+	if err != nil {
+		return nil, err
+	}
+
+	regions := append([]Region(nil), opts.IgnoreRegions...)
+	for _, sel := range opts.IgnoreSelectors {
+		x, y, w, h, err := c.BoundingBox(sel)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, Region{X: x, Y: y, Width: w, Height: h})
+	}
+	var viewportWidth float64
+	err = c.Evaluate(`window.innerWidth`, &viewportWidth)
+	if err != nil {
+		return nil, err
+	}
+	scale := 1.0
+	if viewportWidth > 0 {
+		scale = float64(actual.Bounds().Dx()) / viewportWidth
+	}
+	ignored := make([]image.Rectangle, 0, len(regions))
+	for _, r := range regions {
+		ignored = append(ignored, image.Rect(
+			int(r.X*scale), int(r.Y*scale),
+			int((r.X+r.Width)*scale+0.5), int((r.Y+r.Height)*scale+0.5)))
+	}
+
+	data, err := ioutil.ReadFile(diff.Golden)
+	if os.IsNotExist(err) || opts.Update {
+		err = os.MkdirAll(dir, 0755)
+		if err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(diff.Golden, shot, 0644)
+		if err != nil {
+			return nil, err
+		}
+		diff.Match = true
+		return diff, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	golden, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	out, diffPixels, total := diffImages(actual, golden, threshold, ignored)
+	diff.DiffPixels = diffPixels
+	if total > 0 {
+		diff.DiffRatio = float64(diff.DiffPixels) / float64(total)
+	}
+	diff.Match = diff.DiffPixels == 0 || diff.DiffRatio <= opts.MaxDiffRatio
+	if diff.Match {
+		return diff, nil
+	}
+
+	diff.Diff = filepath.Join(dir, name+".diff.png")
+	var buf bytes.Buffer
+	err = png.Encode(&buf, out)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(diff.Diff, buf.Bytes(), 0644)
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, name+".actual.png"), shot, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// diffImages compares the images pixel by pixel outside the ignored rectangles, and returns an image
+// of the differing pixels in red over the faded image a.
+func diffImages(a, b image.Image, threshold float64, ignored []image.Rectangle) (out *image.RGBA, diffPixels int, total int) {
+	bounds := a.Bounds().Union(b.Bounds())
+	out = image.NewRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+			if inRegions(p, ignored) {
+				out.Set(x, y, color.RGBA{0, 0, 255, 64})
+				continue
+			}
+			if !p.In(a.Bounds()) || !p.In(b.Bounds()) || colorDelta(a.At(x, y), b.At(x, y)) > threshold {
+				diffPixels++
+				out.Set(x, y, color.RGBA{255, 0, 0, 255})
+				continue
+			}
+			out.Set(x, y, fade(a.At(x, y)))
+		}
+	}
+	return out, diffPixels, bounds.Dx() * bounds.Dy()
+}
+
+func inRegions(p image.Point, regions []image.Rectangle) bool {
+	for _, r := range regions {
+		if p.In(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// colorDelta returns the perceived difference of the colors from 0 to 1, by their distance in the YIQ color space.
+func colorDelta(a, b color.Color) float64 {
+	r1, g1, b1, _ := a.RGBA()
+	r2, g2, b2, _ := b.RGBA()
+	dr := (float64(r1) - float64(r2)) / 0xffff
+	dg := (float64(g1) - float64(g2)) / 0xffff
+	db := (float64(b1) - float64(b2)) / 0xffff
+	y := dr*0.29889531 + dg*0.58662247 + db*0.11448223
+	i := dr*0.59597799 - dg*0.27417610 - db*0.32180189
+	q := dr*0.21147017 - dg*0.52261711 + db*0.31114694
+	// 35215 is the maximum squared delta of colors of 8 bit channels,
+	// the root makes the delta linear like the threshold it is compared against
+	return math.Sqrt((0.5053*y*y + 0.299*i*i + 0.1957*q*q) / (35215.0 / 255 / 255))
+}
+
+// fade returns the color blended with white, to highlight the differences drawn over it.
+func fade(c color.Color) color.Color {
+	r, g, b, _ := c.RGBA()
+	return color.RGBA{
+		R: uint8(0xff - (0xffff-r)>>8/4),
+		G: uint8(0xff - (0xffff-g)>>8/4),
+		B: uint8(0xff - (0xffff-b)>>8/4),
+		A: 0xff,
+	}
+}
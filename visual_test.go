@@ -0,0 +1,45 @@
+package puppet
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"testing"
+)
+
+func TestColorDelta(t *testing.T) {
+	tests := []struct {
+		a, b color.Color
+		want float64
+	}{
+		{color.Black, color.Black, 0},
+		{color.White, color.White, 0},
+		{color.Black, color.White, 0.966},
+		{color.Gray{0}, color.Gray{51}, 0.193},
+	}
+	for _, tt := range tests {
+		if got := colorDelta(tt.a, tt.b); math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("colorDelta(%v, %v) = %.3f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 4, 4))
+	b := image.NewGray(image.Rect(0, 0, 4, 5))
+	// below the threshold
+	b.SetGray(0, 0, color.Gray{10})
+	// above the threshold, but only once it is compared against the linear delta
+	b.SetGray(1, 0, color.Gray{51})
+	// ignored
+	b.SetGray(3, 3, color.Gray{255})
+
+	_, diffPixels, total := diffImages(a, b, 0.1, []image.Rectangle{image.Rect(3, 3, 4, 4)})
+	// the pixel above the threshold and the row missing from a
+	if diffPixels != 5 {
+		t.Errorf("diffPixels = %d, want 5", diffPixels)
+	}
+	if total != 20 {
+		t.Errorf("total = %d, want 20", total)
+	}
+}
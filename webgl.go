@@ -0,0 +1,90 @@
+package puppet
+
+import (
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/runner"
+)
+
+// GLMode is the graphics backend of the launched browser.
+type GLMode int
+
+// GL modes.
+const (
+	// GLSwiftShader renders WebGL on the CPU with SwiftShader through ANGLE, for servers without GPU.
+	GLSwiftShader GLMode = iota + 1
+	// GLANGLE renders WebGL through ANGLE with its default backend of the platform.
+	GLANGLE
+	// GLGPU renders WebGL on the real GPU, ignoring the blocklist of the browser.
+	GLGPU
+	// GLDisabled disables the GPU and WebGL.
+	GLDisabled
+)
+
+// WithGL launches the browser with the graphics backend, since WebGL is unavailable with the default flags
+// on most servers, which renders maps and 3D pages blank.
+func WithGL(mode GLMode) Option {
+	return func(o *options) {
+		switch mode {
+		case GLSwiftShader:
+			o.flags = append(o.flags,
+				runner.Flag("disable-gpu", false),
+				runner.Flag("use-gl", "angle"),
+				runner.Flag("use-angle", "swiftshader"),
+				runner.Flag("enable-unsafe-swiftshader", true),
+				runner.Flag("ignore-gpu-blocklist", true),
+			)
+		case GLANGLE:
+			o.flags = append(o.flags,
+				runner.Flag("disable-gpu", false),
+				runner.Flag("use-gl", "angle"),
+				runner.Flag("ignore-gpu-blocklist", true),
+			)
+		case GLGPU:
+			o.flags = append(o.flags,
+				runner.Flag("disable-gpu", false),
+				runner.Flag("enable-gpu", true),
+				runner.Flag("enable-webgl", true),
+				runner.Flag("ignore-gpu-blocklist", true),
+			)
+		case GLDisabled:
+			o.flags = append(o.flags,
+				runner.Flag("disable-gpu", true),
+				runner.Flag("disable-webgl", true),
+			)
+		}
+	}
+}
+
+// WebGLInfo is the WebGL implementation of a page.
+type WebGLInfo struct {
+	// Supported reports whether WebGL is available, the other fields are empty if not.
+	Supported bool   `json:"supported"`
+	Version   string `json:"version"`
+	Vendor    string `json:"vendor"`
+	Renderer  string `json:"renderer"`
+}
+
+// WebGLInfo returns the WebGL implementation of the current page, with the unmasked vendor and renderer if exposed,
+// e.g. "Google Inc. (Google)" and "ANGLE (Google, Vulkan 1.3.0 (SwiftShader Device (Subzero)))".
+func (c *Puppet) WebGLInfo() (info *WebGLInfo, err error) {
+	return info, c.run(c.ctx,
+		chromedp.Evaluate(`(function () {
+	var canvas = document.createElement("canvas");
+	var gl = canvas.getContext("webgl2") || canvas.getContext("webgl") || canvas.getContext("experimental-webgl");
+	if (!gl) {
+		return { supported: false };
+	}
+	var info = {
+		supported: true,
+		version: gl.getParameter(gl.VERSION),
+		vendor: gl.getParameter(gl.VENDOR),
+		renderer: gl.getParameter(gl.RENDERER)
+	};
+	var debug = gl.getExtension("WEBGL_debug_renderer_info");
+	if (debug) {
+		info.vendor = gl.getParameter(debug.UNMASKED_VENDOR_WEBGL);
+		info.renderer = gl.getParameter(debug.UNMASKED_RENDERER_WEBGL);
+	}
+	return info;
+})()`, &info))
+}
@@ -0,0 +1,74 @@
+package puppet
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	"github.com/chromedp/cdproto"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+)
+
+// WebSocketFrame is a frame sent or received by a WebSocket of a page.
+type WebSocketFrame struct {
+	// URL is the url of the WebSocket.
+	URL string
+	// Sent reports whether the page sent the frame, or else received it.
+	Sent bool
+	// Opcode is the opcode of the frame, 1 for text and 2 for binary frames.
+	Opcode int
+	// Data is the payload of the frame, binary payloads are decoded from base64.
+	Data []byte
+	Time time.Time
+}
+
+// Text reports whether the frame is a text frame.
+func (f *WebSocketFrame) Text() bool {
+	return f.Opcode == 1
+}
+
+// OnWebSocketFrame calls fn with every frame sent or received by the WebSockets of the current target.
+func (c *Puppet) OnWebSocketFrame(fn func(*WebSocketFrame)) (err error) {
+	urls := map[network.RequestID]string{}
+	return c.listen(func(ctx context.Context, h cdp.Executor, ev interface{}) {
+		var id network.RequestID
+		var resp *network.WebSocketFrame
+		sent := false
+		switch ev := ev.(type) {
+		case *network.EventWebSocketCreated:
+			urls[ev.RequestID] = ev.URL
+			return
+		case *network.EventWebSocketClosed:
+			delete(urls, ev.RequestID)
+			return
+		case *network.EventWebSocketFrameSent:
+			id, resp, sent = ev.RequestID, ev.Response, true
+		case *network.EventWebSocketFrameReceived:
+			id, resp = ev.RequestID, ev.Response
+		default:
+			return
+		}
+		if resp == nil {
+			return
+		}
+		frame := &WebSocketFrame{
+			URL:    urls[id],
+			Sent:   sent,
+			Opcode: int(resp.Opcode),
+			Data:   []byte(resp.PayloadData),
+			Time:   time.Now(),
+		}
+		if frame.Opcode == 2 {
+			if data, err := base64.StdEncoding.DecodeString(resp.PayloadData); err == nil {
+				frame.Data = data
+			}
+		}
+		fn(frame)
+	},
+		cdproto.EventNetworkWebSocketCreated,
+		cdproto.EventNetworkWebSocketClosed,
+		cdproto.EventNetworkWebSocketFrameSent,
+		cdproto.EventNetworkWebSocketFrameReceived,
+	)
+}
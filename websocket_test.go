@@ -0,0 +1,36 @@
+package puppet
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnWebSocketFrame(t *testing.T) {
+	p, b := newFakePuppet(t)
+	frames := make(chan *WebSocketFrame, 2)
+	err := p.OnWebSocketFrame(func(f *WebSocketFrame) {
+		frames <- f
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.emit("Network.webSocketCreated", `{"requestId":"1","url":"wss://example.com/ws"}`)
+	b.emit("Network.webSocketFrameSent", `{"requestId":"1","response":{"opcode":1,"mask":true,"payloadData":"ping"}}`)
+	b.emit("Network.webSocketFrameReceived", `{"requestId":"1","response":{"opcode":2,"mask":false,"payloadData":"AAE="}}`)
+
+	var sent, received *WebSocketFrame
+	for _, f := range []**WebSocketFrame{&sent, &received} {
+		select {
+		case *f = <-frames:
+		case <-time.After(time.Second):
+			t.Fatal("frame not received in time")
+		}
+	}
+	if sent.URL != "wss://example.com/ws" || !sent.Sent || !sent.Text() || string(sent.Data) != "ping" {
+		t.Errorf("got sent frame %+v", sent)
+	}
+	if received.Sent || received.Text() || string(received.Data) != "\x00\x01" {
+		t.Errorf("got received frame %+v", received)
+	}
+}
@@ -0,0 +1,100 @@
+package puppet
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// WindowState is the state of a browser window.
+type WindowState = browser.WindowState
+
+// Browser window states.
+const (
+	WindowStateNormal     = browser.WindowStateNormal
+	WindowStateMinimized  = browser.WindowStateMinimized
+	WindowStateMaximized  = browser.WindowStateMaximized
+	WindowStateFullscreen = browser.WindowStateFullscreen
+)
+
+// Bounds is the position, size and state of a browser window.
+type Bounds struct {
+	Left   int64
+	Top    int64
+	Width  int64
+	Height int64
+	State  WindowState
+}
+
+// WindowBounds retrieves the bounds of the browser window of the current target.
+func (c *Puppet) WindowBounds() (bounds Bounds, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		_, b, err := browser.GetWindowForTarget().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		bounds = Bounds{
+			Left:   b.Left,
+			Top:    b.Top,
+			Width:  b.Width,
+			Height: b.Height,
+			State:  b.WindowState,
+		}
+		return nil
+	}))
+	return bounds, err
+}
+
+// SetWindowBounds sets the bounds of the browser window of the current target,
+// the position and size are only applied in the normal state.
+func (c *Puppet) SetWindowBounds(bounds Bounds) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		id, cur, err := browser.GetWindowForTarget().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		if bounds.State == "" {
+			bounds.State = WindowStateNormal
+		}
+		if bounds.State != WindowStateNormal {
+			return browser.SetWindowBounds(id, &browser.Bounds{
+				WindowState: bounds.State,
+			}).Do(ctx, h)
+		}
+
+		// the window has to be restored before it can be moved or resized
+		if cur.WindowState != WindowStateNormal {
+			err = browser.SetWindowBounds(id, &browser.Bounds{
+				WindowState: WindowStateNormal,
+			}).Do(ctx, h)
+			if err != nil {
+				return err
+			}
+		}
+		return browser.SetWindowBounds(id, &browser.Bounds{
+			Left:        bounds.Left,
+			Top:         bounds.Top,
+			Width:       bounds.Width,
+			Height:      bounds.Height,
+			WindowState: WindowStateNormal,
+		}).Do(ctx, h)
+	}))
+}
+
+// SetWindowState sets the state of the browser window of the current target, keeping its position and size.
+func (c *Puppet) SetWindowState(state WindowState) (err error) {
+	return c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		id, _, err := browser.GetWindowForTarget().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		return browser.SetWindowBounds(id, &browser.Bounds{
+			WindowState: state,
+		}).Do(ctx, h)
+	}))
+}
@@ -0,0 +1,115 @@
+package puppet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// FrameInfo is the information of a frame.
+type FrameInfo struct {
+	ID       string
+	ParentID string
+	Name     string
+	URL      string
+}
+
+// Frames returns the frames of the current target, the main frame first.
+func (c *Puppet) Frames() (frames []FrameInfo, err error) {
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		tree, err := page.GetFrameTree().
+			Do(ctx, h)
+		if err != nil {
+			return err
+		}
+		var walk func(t *page.FrameTree)
+		walk = func(t *page.FrameTree) {
+			frames = append(frames, FrameInfo{
+				ID:       string(t.Frame.ID),
+				ParentID: string(t.Frame.ParentID),
+				Name:     t.Frame.Name,
+				URL:      t.Frame.URL,
+			})
+			for _, child := range t.ChildFrames {
+				walk(child)
+			}
+		}
+		walk(tree)
+		return nil
+	}))
+	return frames, err
+}
+
+// World is an isolated javascript world of a frame, evaluations in it never run in another frame.
+// The world is destroyed when the frame navigates, after which evaluations fail.
+type World struct {
+	c       *Puppet
+	frameID string
+	name    string
+	id      runtime.ExecutionContextID
+}
+
+// World creates an isolated javascript world with the name in the frame with the specified id.
+func (c *Puppet) World(frameID string, name string) (w *World, err error) {
+	w = &World{
+		c:       c,
+		frameID: frameID,
+		name:    name,
+	}
+	err = c.run(c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		w.id, err = page.CreateIsolatedWorld(cdp.FrameID(frameID)).
+			WithWorldName(name).
+			Do(ctx, h)
+		return err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// FrameID returns the id of the frame of the world.
+func (w *World) FrameID() string {
+	return w.frameID
+}
+
+// ID returns the execution context id of the world.
+func (w *World) ID() int64 {
+	return int64(w.id)
+}
+
+// Evaluate is an action to evaluate the Javascript expression in the world, unmarshaling the result of the script evaluation to res.
+func (w *World) Evaluate(expression string, res interface{}) (err error) {
+	return w.c.run(w.c.ctx, chromedp.ActionFunc(func(ctx context.Context, h cdp.Executor) error {
+		v, exp, err := runtime.Evaluate(expression).
+			WithContextID(w.id).
+			WithReturnByValue(true).
+			WithAwaitPromise(true).
+			Do(ctx, h)
+		if err != nil {
+			return fmt.Errorf("evaluate in world %q of frame %s: %v", w.name, w.frameID, err)
+		}
+		if exp != nil {
+			return exp
+		}
+		if res == nil || v.Type == runtime.TypeUndefined {
+			return nil
+		}
+		return json.Unmarshal(v.Value, res)
+	}))
+}
+
+// mainFrameID returns the id of the main frame.
+func mainFrameID(ctx context.Context, h cdp.Executor) string {
+	tree, err := page.GetFrameTree().
+		Do(ctx, h)
+	if err != nil {
+		return ""
+	}
+	return string(tree.Frame.ID)
+}